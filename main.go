@@ -7,23 +7,168 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/palantir/outparamcheck/outparamcheck"
 )
 
+const configFlagUsage = "JSON configuration or '@' followed by path to a configuration file (@pathToJsonFile, or @- to read from standard input)"
+
+var presetFlagUsage = fmt.Sprintf("name of the preset that provides the built-in configuration (one of %s)",
+	strings.Join(outparamcheck.Presets(), ", "))
+
 func main() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
-	cfgPath := ""
-	fset := flag.CommandLine
-	fset.StringVar(&cfgPath, "config", "", "JSON configuration or '@' followed by path to a configuration file (@pathToJsonFile)")
-	flag.Parse()
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		err = runConfigCmd(os.Args[2:])
+	} else if len(os.Args) > 1 && os.Args[1] == "suppressions" {
+		err = runSuppressionsCmd(os.Args[2:])
+	} else if len(os.Args) > 1 && os.Args[1] == "discover" {
+		err = runDiscoverCmd(os.Args[2:])
+	} else {
+		var opts outparamcheck.Options
+		fset := flag.CommandLine
+		fset.StringVar(&opts.Config, "config", "", configFlagUsage)
+		fset.StringVar(&opts.Preset, "preset", outparamcheck.DefaultPreset, presetFlagUsage)
+		fset.StringVar(&opts.Baseline, "baseline", "", "path of a baseline file whose errors are not reported")
+		fset.StringVar(&opts.WriteBaseline, "write-baseline", "", "path of the baseline file to write all errors to instead of reporting them")
+		fset.StringVar(&opts.Allowlist, "allowlist", "", "path of an allowlist file of the fingerprints of errors that are not reported")
+		fset.StringVar(&opts.WriteAllowlist, "write-allowlist", "", "path of the allowlist file to write the fingerprints of all errors to instead of reporting them")
+		fset.BoolVar(&opts.SkipVendor, "skip-vendor", true, "do not check the packages in vendor directories")
+		fset.BoolVar(&opts.SkipTestdata, "skip-testdata", true, "do not check the packages in testdata directories")
+		fset.StringVar(&opts.Suppressions, "suppressions", "", "path of a suppression file whose suppressions that have not expired suppress the errors that they match")
+		fset.StringVar(&opts.DiffBase, "diff-base", "", "git revision (such as origin/main) relative to which only the errors on added or modified lines are reported")
+		tests := fset.Bool("tests", true, "check test files")
+		includeTestFiles := fset.Bool("include-test-files", true, "check the files of external test packages (packages whose name has the suffix _test)")
+		fset.Var((*stringsFlag)(&opts.SkipPackages), "skip-pkgs", "pattern of the import paths of the packages that are not loaded or checked (can be repeated)")
+		fset.Var((*stringsFlag)(&opts.SkipFiles), "skip-files", "regular expression or glob pattern of the files that are not checked (can be repeated)")
+		fset.BoolVar(&opts.InferWrappers, "infer-wrappers", false, "check the callers of functions that pass one of their parameters to an output parameter instead of the functions themselves")
+		fset.StringVar(&opts.WriteWrapperRules, "write-wrapper-rules", "", "path of the configuration file to write rules for the exported wrappers of the checked packages to")
+		fset.StringVar(&opts.Mode, "mode", outparamcheck.ModeAST, fmt.Sprintf("analysis mode that decides whether arguments are addresses (%s or %s)", outparamcheck.ModeAST, outparamcheck.ModeSSA))
+		fset.IntVar(&opts.MaxIssues, "max-issues", 0, "maximum number of errors that may be reported without failing")
+		fset.StringVar(&opts.Ceilings, "ceilings", "", "path of a file of the maximum numbers of errors of packages, which is used instead of -max-issues")
+		fset.BoolVar(&opts.UpdateCeilings, "update-ceilings", false, "lower the ceilings of the -ceilings file to the current numbers of errors (or create the file)")
+		fset.Var((*stringsFlag)(&opts.Checks), "check", fmt.Sprintf("name of an optional subcheck to run (one of %s; can be repeated)", strings.Join(outparamcheck.Checks(), ", ")))
+		fset.BoolVar(&opts.Strict, "strict", false, "also report *&x and the literal nil passed to output parameters")
+		fset.BoolVar(&opts.Stdin, "stdin", false, "check the source of a single Go file read from standard input instead of packages")
+		fset.StringVar(&opts.PackagePath, "package-path", "", "path of the Go file (or of the directory of the package) that the source read from standard input is checked as")
+		flag.Parse()
+		opts.SkipTests = !*tests
+		opts.SkipExternalTests = !*includeTestFiles
 
-	err := outparamcheck.Run(cfgPath, flag.Args())
+		err = outparamcheck.Run(opts, flag.Args())
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// runConfigCmd runs the "config" command, whose first argument is the name of the subcommand to run.
+func runConfigCmd(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: outparamcheck config (print [-config <config>] [-preset <preset>] [dir] | migrate [-w] <file> | schema | verify [-config <config>] [packages])")
+	}
+
+	switch args[0] {
+	case "print":
+		var opts outparamcheck.Options
+		fset := flag.NewFlagSet("config print", flag.ExitOnError)
+		fset.StringVar(&opts.Config, "config", "", configFlagUsage)
+		fset.StringVar(&opts.Preset, "preset", outparamcheck.DefaultPreset, presetFlagUsage)
+		_ = fset.Parse(args[1:])
+
+		cfg, err := outparamcheck.LoadConfig(opts.Config, opts.Preset)
+		if err != nil {
+			return err
+		}
+		dir := "."
+		if fset.NArg() > 0 {
+			dir = fset.Arg(0)
+		}
+		cfg, err = outparamcheck.ConfigForDir(cfg, dir)
+		if err != nil {
+			return err
+		}
+		return cfg.Print(os.Stdout)
+	case "migrate":
+		write := false
+		fset := flag.NewFlagSet("config migrate", flag.ExitOnError)
+		fset.BoolVar(&write, "w", false, "write the migrated configuration to the file instead of standard output")
+		_ = fset.Parse(args[1:])
+		if fset.NArg() != 1 {
+			return fmt.Errorf("usage: outparamcheck config migrate [-w] <file>")
+		}
+
+		cfgPath := fset.Arg(0)
+		cfgBytes, err := ioutil.ReadFile(cfgPath)
+		if err != nil {
+			return err
+		}
+		migrated, err := outparamcheck.MigrateConfig(string(cfgBytes))
+		if err != nil {
+			return err
+		}
+		if write {
+			return ioutil.WriteFile(cfgPath, migrated, 0644)
+		}
+		_, err = os.Stdout.Write(migrated)
+		return err
+	case "verify":
+		opts := outparamcheck.Options{SkipVendor: true, SkipTestdata: true}
+		fset := flag.NewFlagSet("config verify", flag.ExitOnError)
+		fset.StringVar(&opts.Config, "config", "", configFlagUsage)
+		_ = fset.Parse(args[1:])
+
+		return outparamcheck.VerifyConfig(opts, fset.Args())
+	case "schema":
+		_, err := os.Stdout.Write(outparamcheck.ConfigSchema())
+		return err
+	default:
+		return fmt.Errorf("unknown config subcommand %q", args[0])
+	}
+}
+
+// runSuppressionsCmd runs the "suppressions" command, which lists the suppressions that are in effect for the provided
+// packages.
+func runSuppressionsCmd(args []string) error {
+	opts := outparamcheck.Options{SkipVendor: true, SkipTestdata: true}
+	fset := flag.NewFlagSet("suppressions", flag.ExitOnError)
+	fset.StringVar(&opts.Baseline, "baseline", "", "path of a baseline file whose findings are listed")
+	fset.StringVar(&opts.Allowlist, "allowlist", "", "path of an allowlist file whose fingerprints are listed")
+	fset.StringVar(&opts.Suppressions, "suppressions", "", "path of a suppression file whose suppressions that have not expired are listed")
+	fset.Var((*stringsFlag)(&opts.SkipPackages), "skip-pkgs", "pattern of the import paths of the packages that are not loaded (can be repeated)")
+	_ = fset.Parse(args)
+
+	return outparamcheck.ListSuppressions(opts, fset.Args())
+}
+
+// runDiscoverCmd runs the "discover" command, which prints suggested rules for the functions and methods of the provided
+// packages that look like decode functions.
+func runDiscoverCmd(args []string) error {
+	opts := outparamcheck.Options{SkipVendor: true, SkipTestdata: true}
+	fset := flag.NewFlagSet("discover", flag.ExitOnError)
+	fset.StringVar(&opts.Config, "config", "", configFlagUsage)
+	fset.StringVar(&opts.Preset, "preset", outparamcheck.DefaultPreset, presetFlagUsage)
+	fset.Var((*stringsFlag)(&opts.SkipPackages), "skip-pkgs", "pattern of the import paths of the packages that are not loaded (can be repeated)")
+	_ = fset.Parse(args)
+
+	return outparamcheck.DiscoverRules(opts, fset.Args())
+}
+
+// stringsFlag is a flag that can be specified multiple times, whose values are collected in order.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
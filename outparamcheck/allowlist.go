@@ -0,0 +1,152 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// fingerprintLen is the number of hexadecimal digits of a fingerprint.
+const fingerprintLen = 16
+
+// fingerprint returns the fingerprint of the provided error, which is derived from the rule that matched the called
+// function, the argument, the subcheck that reported it, the slash-separated path of the file relative to the working
+// directory and the source line with its whitespace normalized and its trailing comment removed. Errors that no rule
+// matched, such as those of signature rules, use the name of the called function instead. Fingerprints do not depend
+// on line numbers, so they continue to identify an error when the lines of its file are moved. Errors for the same call
+// that occur on identical lines of the same file have the same fingerprint.
+func fingerprint(err OutParamError) string {
+	finding := newBaselineFinding(err)
+	// the short name of the called function does not distinguish functions such as json.Unmarshal and yaml.Unmarshal
+	rule := err.Rule
+	if rule == "" {
+		rule = finding.Method
+	}
+	fields := []string{
+		rule,
+		fmt.Sprint(finding.Argument),
+		finding.File,
+		normalizedLine(err.Line),
+	}
+	// the fingerprints of the errors of output parameters do not depend on the check so that they remain stable
+	if finding.Check != "" {
+		fields = append(fields, finding.Check)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])[:fingerprintLen]
+}
+
+// normalizedLine returns the provided source line without its trailing comment and with every run of whitespace
+// replaced by a single space.
+func normalizedLine(line string) string {
+	if comment := strings.Index(line, "//"); comment != -1 {
+		line = line[:comment]
+	}
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// allowlist is a set of the fingerprints of errors that are not reported.
+type allowlist map[string]bool
+
+// allowlistEntry is a fingerprint of an allowlist file.
+type allowlistEntry struct {
+	// Line is the number of the line of the fingerprint in the allowlist file.
+	Line        int
+	Fingerprint string
+	// Comment is the comment that follows the fingerprint, which describes the error that it identifies if the file
+	// was written using WriteAllowlist.
+	Comment string
+}
+
+// readAllowlist reads the allowlist file with the provided path and returns the set of its fingerprints.
+func readAllowlist(path string) (allowlist, error) {
+	entries, err := readAllowlistEntries(path)
+	if err != nil {
+		return nil, err
+	}
+	a := make(allowlist)
+	for _, entry := range entries {
+		a[entry.Fingerprint] = true
+	}
+	return a, nil
+}
+
+// readAllowlistEntries reads the allowlist file with the provided path, which contains a fingerprint on every line
+// that is not empty. Everything after a '#' is a comment.
+func readAllowlistEntries(path string) ([]allowlistEntry, error) {
+	allowlistBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read allowlist %s", path)
+	}
+	var entries []allowlistEntry
+	scanner := bufio.NewScanner(bytes.NewReader(allowlistBytes))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		comment := ""
+		if i := strings.Index(line, "#"); i != -1 {
+			line, comment = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if _, err := hex.DecodeString(line); err != nil || len(line) != fingerprintLen {
+			return nil, errors.Errorf("allowlist %s: line %d: %q is not a fingerprint", path, lineNum, line)
+		}
+		entries = append(entries, allowlistEntry{Line: lineNum, Fingerprint: line, Comment: comment})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read allowlist %s", path)
+	}
+	return entries, nil
+}
+
+// writeAllowlist writes an allowlist file with the provided path that contains the fingerprints of the provided
+// errors, each of which is followed by a comment that describes the error.
+func writeAllowlist(path string, errs []OutParamError) error {
+	lines := make(map[string]string)
+	for _, err := range errs {
+		finding := newBaselineFinding(err)
+		lines[fingerprint(err)] = fmt.Sprintf("# %s: %s", finding.File, normalizedLine(err.Line))
+	}
+	fingerprints := make([]string, 0, len(lines))
+	for fp := range lines {
+		fingerprints = append(fingerprints, fp)
+	}
+	sort.Slice(fingerprints, func(i, j int) bool {
+		if lines[fingerprints[i]] != lines[fingerprints[j]] {
+			return lines[fingerprints[i]] < lines[fingerprints[j]]
+		}
+		return fingerprints[i] < fingerprints[j]
+	})
+	buf := &bytes.Buffer{}
+	for _, fp := range fingerprints {
+		fmt.Fprintf(buf, "%s  %s\n", fp, lines[fp])
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write allowlist %s", path)
+	}
+	return nil
+}
+
+// filter returns the provided errors whose fingerprints are not in the allowlist.
+func (a allowlist) filter(errs []OutParamError) []OutParamError {
+	var filtered []OutParamError
+	for _, err := range errs {
+		if !a[fingerprint(err)] {
+			filtered = append(filtered, err)
+		}
+	}
+	return filtered
+}
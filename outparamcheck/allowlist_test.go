@@ -0,0 +1,70 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowlist(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	absDir, err := filepath.Abs(tmpDir)
+	require.NoError(t, err)
+	newErr := func(file string, line int, text string) OutParamError {
+		return OutParamError{
+			Pos:      token.Position{Filename: filepath.Join(absDir, file), Line: line},
+			Line:     text,
+			Method:   "Unmarshal",
+			Rule:     "encoding/json.Unmarshal",
+			Argument: 1,
+		}
+	}
+	allowlistPath := path.Join(tmpDir, "allowlist.txt")
+	require.NoError(t, writeAllowlist(allowlistPath, []OutParamError{
+		newErr("main.go", 10, "json.Unmarshal(data, x)"),
+		newErr("main.go", 12, "json.Unmarshal(data, x)"),
+	}))
+
+	allowlistBytes, err := ioutil.ReadFile(allowlistPath)
+	require.NoError(t, err)
+	fp := fingerprint(newErr("main.go", 10, "json.Unmarshal(data, x)"))
+	assert.Equal(t, fp+"  # "+filepath.ToSlash(path.Join(tmpDir, "main.go"))+": json.Unmarshal(data, x)\n", string(allowlistBytes))
+
+	a, err := readAllowlist(allowlistPath)
+	require.NoError(t, err)
+	errs := []OutParamError{
+		// the line has moved and its whitespace and comment have changed
+		newErr("main.go", 20, "json.Unmarshal(data,   x) // decode"),
+		newErr("main.go", 22, "json.Unmarshal(data, y)"),
+		newErr("other.go", 10, "json.Unmarshal(data, x)"),
+	}
+	// a function with the same name that another rule matches on the same line
+	yamlErr := newErr("main.go", 10, "json.Unmarshal(data, x)")
+	yamlErr.Rule = "gopkg.in/yaml.v3.Unmarshal"
+	errs = append(errs, yamlErr)
+	assert.Equal(t, errs[1:], a.filter(errs))
+}
+
+func TestReadAllowlistErrors(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	allowlistPath := path.Join(tmpDir, "allowlist.txt")
+	require.NoError(t, ioutil.WriteFile(allowlistPath, []byte("# allowed errors\n\n0123456789abcdef # main.go\nmain.go:10\n"), 0644))
+	_, err = readAllowlist(allowlistPath)
+	assert.EqualError(t, err, "allowlist "+allowlistPath+`: line 4: "main.go:10" is not a fingerprint`)
+}
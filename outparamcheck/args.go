@@ -0,0 +1,344 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"encoding/json"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// variadicArgSuffix is the suffix of the arguments that specify that every argument from an index onward is an output
+// parameter, such as "0+".
+const variadicArgSuffix = "+"
+
+// emptyInterfaceArgs is the argument that specifies that every argument whose parameter is an empty interface is an
+// output parameter.
+const emptyInterfaceArgs = "any"
+
+// Arg specifies one or more arguments of a call that are output parameters. In configuration, an Arg is either an
+// argument index or a string consisting of an argument index followed by "+", which specifies the argument at the
+// index and every argument after it. Negative indices count from the end of the arguments, so -1 is the last argument.
+// The string "any" specifies every argument whose parameter is an empty interface (interface{} or any). An Arg can also
+// be an object whose "index" is one of the above and whose "kind" is the kind of pointer that the arguments must be.
+type Arg struct {
+	// Index is the index of the argument. A negative index is relative to the number of arguments of the call.
+	Index int
+	// Variadic is true if every argument after the argument at Index is also an output parameter.
+	Variadic bool
+	// EmptyInterfaces is true if the Arg specifies every argument whose parameter is an empty interface, in which case
+	// Index and Variadic are ignored.
+	EmptyInterfaces bool
+	// Kind is the kind of pointer that the specified arguments must be.
+	Kind ArgKind
+}
+
+// ArgKind is the kind of pointer that an output parameter must be.
+type ArgKind int
+
+const (
+	// ArgKindAny allows any pointer.
+	ArgKindAny ArgKind = iota
+	// ArgKindPtrToStruct requires a pointer to a struct.
+	ArgKindPtrToStruct
+	// ArgKindPtrToMap requires a pointer to a map.
+	ArgKindPtrToMap
+	// ArgKindPtrToSlice requires a pointer to a slice.
+	ArgKindPtrToSlice
+	// ArgKindRef allows any pointer as well as maps and slices, which refer to the values that they hold without being
+	// pointers.
+	ArgKindRef
+)
+
+var argKindNames = map[ArgKind]string{
+	ArgKindAny:         "ptr",
+	ArgKindPtrToStruct: "ptr-to-struct",
+	ArgKindPtrToMap:    "ptr-to-map",
+	ArgKindPtrToSlice:  "ptr-to-slice",
+	ArgKindRef:         "ref",
+}
+
+// argKindDescriptions are the descriptions of the types required by the argument kinds.
+var argKindDescriptions = map[ArgKind]string{
+	ArgKindPtrToStruct: "a pointer to a struct",
+	ArgKindPtrToMap:    "a pointer to a map",
+	ArgKindPtrToSlice:  "a pointer to a slice",
+	ArgKindRef:         "a pointer, a map or a slice",
+}
+
+func (k ArgKind) String() string {
+	return argKindNames[k]
+}
+
+func (k ArgKind) MarshalText() ([]byte, error) {
+	return marshalEnum(k, argKindNames)
+}
+
+func (k *ArgKind) UnmarshalText(text []byte) error {
+	return unmarshalEnum(text, k, argKindNames)
+}
+
+// matches returns true if the provided type of an argument is of the kind. Arguments whose type is not known or is an
+// interface are considered to match since the type of the value that they hold is not known statically.
+func (k ArgKind) matches(typ types.Type) bool {
+	if k == ArgKindAny || typ == nil || types.IsInterface(typ) {
+		return true
+	}
+	if k == ArgKindRef {
+		return isRef(typ, k)
+	}
+	if basic, ok := typ.Underlying().(*types.Basic); ok && basic.Kind() == types.UnsafePointer {
+		// an unsafe.Pointer may point to a value of any kind
+		return true
+	}
+	ptr, ok := typ.Underlying().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	switch ptr.Elem().Underlying().(type) {
+	case *types.Struct:
+		return k == ArgKindPtrToStruct
+	case *types.Map:
+		return k == ArgKindPtrToMap
+	case *types.Slice:
+		return k == ArgKindPtrToSlice
+	case *types.Interface:
+		// the interface may hold a value of any kind
+		return true
+	default:
+		return false
+	}
+}
+
+// isRef returns true if the provided type of an argument refers to the value that is stored by the callee, which is the
+// case for pointers and, if the provided kind is ArgKindRef, for maps and slices.
+func isRef(typ types.Type, k ArgKind) bool {
+	if typ == nil {
+		return false
+	}
+	if isPointer(typ) {
+		return true
+	}
+	switch typ.Underlying().(type) {
+	case *types.Map, *types.Slice:
+		return k == ArgKindRef
+	default:
+		return false
+	}
+}
+
+// isPointer returns true if the provided type is a pointer, which includes unsafe.Pointer, the type parameters whose
+// constraints only permit pointers, such as the PT of "[T any, PT interface{ *T }]", and the interfaces of protocol
+// buffer messages.
+func isPointer(typ types.Type) bool {
+	switch typ := types.Unalias(typ).(type) {
+	case *types.TypeParam:
+		return permitsOnlyPointers(typ.Constraint())
+	case *types.Named:
+		if obj := typ.Obj(); obj.Pkg() != nil && protoMessageTypes[canonicalKey(obj.Pkg().Path()+"."+obj.Name())] {
+			return true
+		}
+	}
+	switch typ := typ.Underlying().(type) {
+	case *types.Pointer:
+		return true
+	case *types.Basic:
+		return typ.Kind() == types.UnsafePointer
+	default:
+		return false
+	}
+}
+
+// protoMessageTypes are the interfaces of protocol buffer messages, which are only implemented by pointers since the
+// methods of generated messages have pointer receivers. The proto.Message types of google.golang.org/protobuf and
+// github.com/golang/protobuf are aliases of these interfaces.
+var protoMessageTypes = map[string]bool{
+	"google.golang.org/protobuf/reflect/protoreflect.ProtoMessage": true,
+	"google.golang.org/protobuf/runtime/protoiface.MessageV1":      true,
+	"github.com/golang/protobuf/proto.Message":                     true,
+}
+
+// indexArgs returns the arguments for the provided argument indices.
+func indexArgs(indices ...int) []Arg {
+	args := make([]Arg, len(indices))
+	for i, index := range indices {
+		args[i] = Arg{Index: index}
+	}
+	return args
+}
+
+func (a Arg) String() string {
+	if a.EmptyInterfaces {
+		return emptyInterfaceArgs
+	}
+	if a.Variadic {
+		return strconv.Itoa(a.Index) + variadicArgSuffix
+	}
+	return strconv.Itoa(a.Index)
+}
+
+func (a Arg) MarshalJSON() ([]byte, error) {
+	if a.Kind != ArgKindAny {
+		withoutKind := a
+		withoutKind.Kind = ArgKindAny
+		return json.Marshal(argObject{Index: withoutKind, Kind: a.Kind})
+	}
+	if a.EmptyInterfaces || a.Variadic {
+		return json.Marshal(a.String())
+	}
+	return json.Marshal(a.Index)
+}
+
+func (a *Arg) UnmarshalJSON(data []byte) error {
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		var obj argObject
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		*a = obj.Index
+		a.Kind = obj.Kind
+		return nil
+	}
+	var index int
+	if err := json.Unmarshal(data, &index); err == nil {
+		*a = Arg{Index: index}
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return errors.Errorf("argument must be an integer or a string, was %s", data)
+	}
+	if str == emptyInterfaceArgs {
+		*a = Arg{EmptyInterfaces: true}
+		return nil
+	}
+	if !strings.HasSuffix(str, variadicArgSuffix) {
+		return errors.Errorf("invalid argument %q: must be an index followed by %q", str, variadicArgSuffix)
+	}
+	index, err := strconv.Atoi(strings.TrimSuffix(str, variadicArgSuffix))
+	if err != nil {
+		return errors.Errorf("invalid argument %q: must be an index followed by %q", str, variadicArgSuffix)
+	}
+	*a = Arg{Index: index, Variadic: true}
+	return nil
+}
+
+// argObject is the object form of an Arg, which specifies the kind of the arguments in addition to the arguments.
+type argObject struct {
+	// Index is the Arg without a kind.
+	Index Arg     `json:"index"`
+	Kind  ArgKind `json:"kind,omitempty"`
+}
+
+// indices returns the indices of the arguments specified by the Arg for a call with the provided number of arguments
+// to a function with the provided signature, which is nil if it is not known.
+func (a Arg) indices(numArgs int, sig *types.Signature) []int {
+	if a.EmptyInterfaces {
+		return emptyInterfaceIndices(numArgs, sig)
+	}
+	index := a.Index
+	if index < 0 {
+		index += numArgs
+	}
+	if index < 0 || index >= numArgs {
+		return nil
+	}
+	if !a.Variadic {
+		return []int{index}
+	}
+	var indices []int
+	for i := index; i < numArgs; i++ {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+// minArgs returns the minimum number of arguments that a call must have for the Arg to specify one of them.
+func (a Arg) minArgs() int {
+	if a.EmptyInterfaces {
+		return 0
+	}
+	if a.Index < 0 {
+		return -a.Index
+	}
+	return a.Index + 1
+}
+
+// less returns true if the Arg sorts before the provided Arg.
+func (a Arg) less(other Arg) bool {
+	if a.EmptyInterfaces || other.EmptyInterfaces {
+		return !a.EmptyInterfaces && other.EmptyInterfaces
+	}
+	if a.Index != other.Index {
+		return a.Index < other.Index
+	}
+	return !a.Variadic && other.Variadic
+}
+
+// emptyInterfaceIndices returns the indices of the arguments of a call with the provided number of arguments to a
+// function with the provided signature whose parameters are empty interfaces. The arguments that are passed to a
+// variadic parameter of type ...interface{} are all included.
+func emptyInterfaceIndices(numArgs int, sig *types.Signature) []int {
+	if sig == nil {
+		return nil
+	}
+	params := sig.Params()
+	var indices []int
+	for i := 0; i < numArgs && i < params.Len(); i++ {
+		typ := params.At(i).Type()
+		if sig.Variadic() && i == params.Len()-1 {
+			if slice, ok := typ.(*types.Slice); ok && isEmptyInterface(slice.Elem()) {
+				for j := i; j < numArgs; j++ {
+					indices = append(indices, j)
+				}
+			}
+			break
+		}
+		if isEmptyInterface(typ) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// isEmptyInterface returns true if the provided type is an interface without methods.
+func isEmptyInterface(typ types.Type) bool {
+	iface, ok := typ.Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
+
+// permitsOnlyPointers returns true if every type in the type set of the provided constraint is a pointer. The type set
+// of a constraint is the intersection of the type sets of its elements, so it is enough for one of its elements to only
+// permit pointers.
+func permitsOnlyPointers(constraint types.Type) bool {
+	iface, ok := constraint.Underlying().(*types.Interface)
+	if !ok {
+		return false
+	}
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		switch embedded := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			onlyPointers := embedded.Len() > 0
+			for j := 0; j < embedded.Len(); j++ {
+				onlyPointers = onlyPointers && isPointer(embedded.Term(j).Type())
+			}
+			if onlyPointers {
+				return true
+			}
+		default:
+			if types.IsInterface(embedded) {
+				if permitsOnlyPointers(embedded) {
+					return true
+				}
+			} else if isPointer(embedded) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"encoding/json"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgJSON(t *testing.T) {
+	var args []Arg
+	require.NoError(t, json.Unmarshal([]byte(`[0, "2+", -1, "-2+", "any"]`), &args))
+	assert.Equal(t, []Arg{{Index: 0}, {Index: 2, Variadic: true}, {Index: -1}, {Index: -2, Variadic: true}, {EmptyInterfaces: true}}, args)
+
+	argsJSON, err := json.Marshal(args)
+	require.NoError(t, err)
+	assert.Equal(t, `[0,"2+",-1,"-2+","any"]`, string(argsJSON))
+
+	var kindArgs []Arg
+	require.NoError(t, json.Unmarshal([]byte(`[{"index": 1, "kind": "ptr-to-struct"}, {"index": "any", "kind": "ptr-to-map"}, {"index": 0}]`), &kindArgs))
+	assert.Equal(t, []Arg{{Index: 1, Kind: ArgKindPtrToStruct}, {EmptyInterfaces: true, Kind: ArgKindPtrToMap}, {Index: 0}}, kindArgs)
+
+	kindArgsJSON, err := json.Marshal(kindArgs)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"index":1,"kind":"ptr-to-struct"},{"index":"any","kind":"ptr-to-map"},0]`, string(kindArgsJSON))
+
+	for _, invalid := range []string{`["2"]`, `["+"]`, `["a+"]`, `[true]`, `[{"index": 0, "kind": "struct"}]`} {
+		assert.Error(t, json.Unmarshal([]byte(invalid), &args), invalid)
+	}
+}
+
+func TestArgIndices(t *testing.T) {
+	for i, tc := range []struct {
+		arg     Arg
+		numArgs int
+		want    []int
+	}{
+		{Arg{Index: 1}, 3, []int{1}},
+		{Arg{Index: 3}, 3, nil},
+		{Arg{Index: 1, Variadic: true}, 4, []int{1, 2, 3}},
+		{Arg{Index: 0, Variadic: true}, 0, nil},
+		{Arg{Index: -1}, 3, []int{2}},
+		{Arg{Index: -3}, 3, []int{0}},
+		{Arg{Index: -4}, 3, nil},
+		{Arg{Index: -2, Variadic: true}, 4, []int{2, 3}},
+	} {
+		assert.Equal(t, tc.want, tc.arg.indices(tc.numArgs, nil), "Case %d", i)
+	}
+}
+
+func TestArgIndicesEmptyInterfaces(t *testing.T) {
+	emptyIface := types.NewInterfaceType(nil, nil)
+	params := func(typs ...types.Type) *types.Tuple {
+		var vars []*types.Var
+		for _, typ := range typs {
+			vars = append(vars, types.NewParam(token.NoPos, nil, "", typ))
+		}
+		return types.NewTuple(vars...)
+	}
+	byteSlice := types.NewSlice(types.Typ[types.Byte])
+	arg := Arg{EmptyInterfaces: true}
+
+	unmarshal := types.NewSignatureType(nil, nil, nil, params(byteSlice, emptyIface), nil, false)
+	assert.Equal(t, []int{1}, arg.indices(2, unmarshal))
+
+	sscan := types.NewSignatureType(nil, nil, nil, params(types.Typ[types.String], types.NewSlice(emptyIface)), nil, true)
+	assert.Equal(t, []int{1, 2, 3}, arg.indices(4, sscan))
+
+	compact := types.NewSignatureType(nil, nil, nil, params(byteSlice, byteSlice), nil, false)
+	assert.Empty(t, arg.indices(2, compact))
+	assert.Empty(t, arg.indices(2, nil))
+}
+
+func TestArgKindMatches(t *testing.T) {
+	structType := types.NewStruct(nil, nil)
+	mapType := types.NewMap(types.Typ[types.String], types.Typ[types.Int])
+	sliceType := types.NewSlice(types.Typ[types.Int])
+	emptyIface := types.NewInterfaceType(nil, nil)
+
+	for i, tc := range []struct {
+		kind ArgKind
+		typ  types.Type
+		want bool
+	}{
+		{ArgKindAny, types.NewPointer(types.Typ[types.Int]), true},
+		{ArgKindPtrToStruct, types.NewPointer(structType), true},
+		{ArgKindPtrToStruct, types.NewPointer(mapType), false},
+		{ArgKindPtrToStruct, types.NewPointer(types.Typ[types.Int]), false},
+		{ArgKindPtrToMap, types.NewPointer(mapType), true},
+		{ArgKindPtrToSlice, types.NewPointer(sliceType), true},
+		{ArgKindPtrToSlice, types.NewPointer(types.NewPointer(sliceType)), false},
+		{ArgKindPtrToSlice, types.NewPointer(emptyIface), true},
+		{ArgKindPtrToSlice, emptyIface, true},
+		{ArgKindPtrToSlice, nil, true},
+		{ArgKindRef, types.NewPointer(types.Typ[types.Int]), true},
+		{ArgKindRef, mapType, true},
+		{ArgKindRef, sliceType, true},
+		{ArgKindRef, types.Typ[types.Int], false},
+	} {
+		assert.Equal(t, tc.want, tc.kind.matches(tc.typ), "Case %d", i)
+	}
+}
+
+func TestIsPointer(t *testing.T) {
+	protoMessage := types.NewNamed(
+		types.NewTypeName(token.NoPos, types.NewPackage("google.golang.org/protobuf/reflect/protoreflect", "protoreflect"), "ProtoMessage", nil),
+		types.NewInterfaceType(nil, nil),
+		nil,
+	)
+	otherIface := types.NewNamed(
+		types.NewTypeName(token.NoPos, types.NewPackage("github.com/palantir/example/codec", "codec"), "Message", nil),
+		types.NewInterfaceType(nil, nil),
+		nil,
+	)
+
+	for i, tc := range []struct {
+		typ  types.Type
+		want bool
+	}{
+		{types.NewPointer(types.Typ[types.Int]), true},
+		{types.Typ[types.UnsafePointer], true},
+		{types.Typ[types.Int], false},
+		{protoMessage, true},
+		{otherIface, false},
+	} {
+		assert.Equal(t, tc.want, isPointer(tc.typ), "Case %d", i)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
+	"sort"
+
+	"github.com/dustin/go-humanize"
+)
+
+// perIterationLoopVarsVersion is the first version of Go in which the variables declared by a for statement are
+// declared anew for every iteration rather than shared by all iterations.
+const perIterationLoopVarsVersion = "go1.22"
+
+// checkAsyncLoopVars reports the output parameters of the calls of the provided file that are passed the address of a
+// loop variable and are made asynchronously, either by a go or defer statement or within a function literal that is
+// not called immediately. The loop variables of files whose Go version precedes per-iteration loop variables are shared
+// by all iterations, so the asynchronous call may decode into the variable after the next iteration has assigned it.
+func (v *visitor) checkAsyncLoopVars(file *ast.File) {
+	if !v.sharesLoopVars(file) {
+		return
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		var idents []ast.Expr
+		var body *ast.BlockStmt
+		switch node := node.(type) {
+		case *ast.RangeStmt:
+			if node.Tok == token.DEFINE {
+				idents, body = []ast.Expr{node.Key, node.Value}, node.Body
+			}
+		case *ast.ForStmt:
+			if init, ok := node.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+				idents, body = init.Lhs, node.Body
+			}
+		}
+		loopVars := make(map[*types.Var]bool)
+		for _, expr := range idents {
+			if ident, ok := expr.(*ast.Ident); ok {
+				if obj, ok := v.pkg.TypesInfo.Defs[ident].(*types.Var); ok {
+					loopVars[obj] = true
+				}
+			}
+		}
+		if len(loopVars) > 0 {
+			v.checkLoopBody(body, loopVars, false)
+		}
+		return true
+	})
+}
+
+// sharesLoopVars returns true if the loop variables of the provided file are shared by all iterations, which is the
+// case if the Go version of the file precedes perIterationLoopVarsVersion. The version of the module of the checked
+// package is used if the version of the file is not known, and files of unknown versions are assumed to share loop
+// variables.
+func (v *visitor) sharesLoopVars(file *ast.File) bool {
+	goVersion := v.pkg.TypesInfo.FileVersions[file]
+	if goVersion == "" && v.pkg.Module != nil && v.pkg.Module.GoVersion != "" {
+		goVersion = "go" + v.pkg.Module.GoVersion
+	}
+	return !version.IsValid(goVersion) || version.Compare(goVersion, perIterationLoopVarsVersion) < 0
+}
+
+// checkLoopBody reports the asynchronous calls within the provided node of the body of a loop whose output parameters
+// are passed the address of one of the provided loop variables. If async is true, the node is executed asynchronously.
+func (v *visitor) checkLoopBody(node ast.Node, loopVars map[*types.Var]bool, async bool) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.GoStmt:
+			v.checkLoopBody(node.Call, loopVars, true)
+			return false
+		case *ast.DeferStmt:
+			v.checkLoopBody(node.Call, loopVars, true)
+			return false
+		case *ast.FuncLit:
+			// a function literal that is not called immediately may be called after the iteration
+			v.checkLoopBody(node.Body, loopVars, true)
+			return false
+		case *ast.CallExpr:
+			if lit, ok := ast.Unparen(node.Fun).(*ast.FuncLit); ok {
+				v.checkLoopBody(lit.Body, loopVars, async)
+				for _, arg := range node.Args {
+					v.checkLoopBody(arg, loopVars, async)
+				}
+				return false
+			}
+			if async {
+				v.reportLoopVarAddrs(node, loopVars)
+			}
+		}
+		return true
+	})
+}
+
+// reportLoopVarAddrs reports the output parameters of the provided asynchronous call that are passed the address of
+// one of the provided loop variables.
+func (v *visitor) reportLoopVarAddrs(call *ast.CallExpr, loopVars map[*types.Var]bool) {
+	method, outArgs := v.callOutArgs(call)
+	indices := make([]int, 0, len(outArgs))
+	for i := range outArgs {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	for _, i := range indices {
+		unary, ok := ast.Unparen(call.Args[i]).(*ast.UnaryExpr)
+		if !ok || unary.Op != token.AND {
+			continue
+		}
+		ident, ok := ast.Unparen(unary.X).(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if obj, ok := v.pkg.TypesInfo.Uses[ident].(*types.Var); !ok || !loopVars[obj] {
+			continue
+		}
+		v.report(call.Args[i].Pos(), OutParamError{
+			Method:   method,
+			Rule:     outArgs[i].rule,
+			Argument: i,
+			Severity: SeverityError,
+			Problem: fmt.Sprintf("%s argument of '%s' is the address of the loop variable %s, which the next iteration may assign before the asynchronous call decodes into it; copy it to a variable declared in the loop body",
+				humanize.Ordinal(i+1), method, ident.Name),
+			Check: CheckAsyncLoopVar,
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAsyncLoopVars(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	const src = `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		type Item struct{}
+
+		func main() {
+			var handlers []func()
+			for _, item := range make([]Item, 3) {
+				go json.Unmarshal(nil, &item)
+				go func() {
+					_ = json.Unmarshal(nil, &item)
+				}()
+				handlers = append(handlers, func() {
+					_ = json.Unmarshal(nil, &item)
+				})
+				defer json.Unmarshal(nil, &item)
+				_ = json.Unmarshal(nil, &item)
+				func() {
+					_ = json.Unmarshal(nil, &item)
+				}()
+				item := item
+				go json.Unmarshal(nil, &item)
+			}
+			for i := 0; i < 3; i++ {
+				go json.Unmarshal(nil, &i)
+			}
+		}
+		`
+	cfg := argsConfig(map[string][]int{
+		"encoding/json.Unmarshal": {1},
+	})
+
+	// loop variables are shared by all iterations before Go 1.22
+	pkgs := loadTestPackage(t, tmpDir, "//go:build go1.21\n"+src)
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 202, Line: 14, Column: 28}, Line: "go json.Unmarshal(nil, &item)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' is the address of the loop variable item, which the next iteration may assign before the asynchronous call decodes into it; copy it to a variable declared in the loop body", Check: CheckAsyncLoopVar},
+		{Pos: token.Position{Offset: 254, Line: 16, Column: 30}, Line: "_ = json.Unmarshal(nil, &item)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' is the address of the loop variable item, which the next iteration may assign before the asynchronous call decodes into it; copy it to a variable declared in the loop body", Check: CheckAsyncLoopVar},
+		{Pos: token.Position{Offset: 339, Line: 19, Column: 30}, Line: "_ = json.Unmarshal(nil, &item)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' is the address of the loop variable item, which the next iteration may assign before the asynchronous call decodes into it; copy it to a variable declared in the loop body", Check: CheckAsyncLoopVar},
+		{Pos: token.Position{Offset: 383, Line: 21, Column: 31}, Line: "defer json.Unmarshal(nil, &item)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' is the address of the loop variable item, which the next iteration may assign before the asynchronous call decodes into it; copy it to a variable declared in the loop body", Check: CheckAsyncLoopVar},
+		{Pos: token.Position{Offset: 593, Line: 30, Column: 28}, Line: "go json.Unmarshal(nil, &i)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' is the address of the loop variable i, which the next iteration may assign before the asynchronous call decodes into it; copy it to a variable declared in the loop body", Check: CheckAsyncLoopVar},
+	}, runChecks(t, pkgs, cfg, CheckAsyncLoopVar), "go1.21")
+
+	pkgs = loadTestPackage(t, tmpDir, src)
+	assertOutParamErrors(t, pkgs, nil, runChecks(t, pkgs, cfg, CheckAsyncLoopVar), "go1.22")
+}
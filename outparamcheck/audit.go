@@ -0,0 +1,158 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// activeSuppression is a suppression of errors that is in effect, which is either a directive in a source file, a
+// finding of a baseline, a fingerprint of an allowlist or a suppression of a suppression file that has not expired.
+type activeSuppression struct {
+	// Location is the location of a directive, the file of a baseline finding, the line of an allowlist fingerprint or
+	// the file pattern of a suppression.
+	Location string
+	// Kind is the kind of the suppression, which is the kind of a directive, "baseline", "allowlist" or "suppression".
+	Kind string
+	// Description describes what is suppressed and why.
+	Description string
+}
+
+func (s activeSuppression) String() string {
+	return fmt.Sprintf("%s: %s: %s", s.Location, s.Kind, s.Description)
+}
+
+// ListSuppressions prints every suppression that is in effect for the packages with the provided paths: the directives
+// in their files, the findings of the baseline of the provided options, the fingerprints of the allowlist of the
+// provided options and the suppressions of the suppression file of the provided options that have not expired.
+func ListSuppressions(opts Options, paths []string) error {
+	pkgs, err := load(paths, opts.skippedDirs(), opts.SkipPackages, opts.testsMode(), nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var b baseline
+	if opts.Baseline != "" {
+		if b, err = readBaseline(opts.Baseline); err != nil {
+			return err
+		}
+	}
+	var entries []allowlistEntry
+	if opts.Allowlist != "" {
+		if entries, err = readAllowlistEntries(opts.Allowlist); err != nil {
+			return err
+		}
+	}
+	var suppressions []suppression
+	if opts.Suppressions != "" {
+		if suppressions, err = readSuppressions(opts.Suppressions); err != nil {
+			return err
+		}
+	}
+	for _, s := range activeSuppressions(pkgs, b, opts.Allowlist, entries, suppressions) {
+		fmt.Println(s)
+	}
+	return nil
+}
+
+// activeSuppressions returns the directives in the files of the provided packages followed by the findings of the
+// provided baseline, the provided entries of the allowlist with the provided path and the provided suppressions that
+// have not expired.
+func activeSuppressions(pkgs []*packages.Package, b baseline, allowlistPath string, entries []allowlistEntry, suppressions []suppression) []activeSuppression {
+	wd, wdErr := os.Getwd()
+	relPath := func(path string) string {
+		if wdErr == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil {
+				path = rel
+			}
+		}
+		return filepath.ToSlash(path)
+	}
+
+	var directives []directive
+	// files are visited once even if they are part of multiple packages, such as the test variant of a package
+	visited := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			filename := pkg.Fset.Position(file.Pos()).Filename
+			if visited[filename] {
+				continue
+			}
+			visited[filename] = true
+			directives = append(directives, newFileDirectives(pkg.Fset, file).list...)
+		}
+	}
+	sort.SliceStable(directives, func(i, j int) bool {
+		pi, pj := directives[i].Pos, directives[j].Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+
+	var active []activeSuppression
+	for _, d := range directives {
+		reason := d.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		active = append(active, activeSuppression{
+			Location:    fmt.Sprintf("%s:%d:%d", relPath(d.Pos.Filename), d.Pos.Line, d.Pos.Column),
+			Kind:        d.Kind,
+			Description: reason,
+		})
+	}
+	for _, finding := range b.Findings {
+		description := fmt.Sprintf("argument %d to %s in %q", finding.Argument, finding.Method, finding.Line)
+		if finding.Check != "" {
+			description = fmt.Sprintf("%s in %q", finding.Check, finding.Line)
+		}
+		active = append(active, activeSuppression{
+			Location:    finding.File,
+			Kind:        "baseline",
+			Description: description,
+		})
+	}
+	for _, entry := range entries {
+		description := entry.Fingerprint
+		if entry.Comment != "" {
+			description = fmt.Sprintf("%s (%s)", entry.Fingerprint, entry.Comment)
+		}
+		active = append(active, activeSuppression{
+			Location:    fmt.Sprintf("%s:%d", relPath(allowlistPath), entry.Line),
+			Kind:        "allowlist",
+			Description: description,
+		})
+	}
+	t := now()
+	for _, s := range suppressions {
+		if s.expired(t) {
+			continue
+		}
+		var details []string
+		if s.Method != "" {
+			details = append(details, "method "+s.Method)
+		}
+		if s.Line != "" {
+			details = append(details, fmt.Sprintf("line %q", s.Line))
+		}
+		details = append(details, "owner "+s.Owner)
+		if s.Expires != "" {
+			details = append(details, "expires "+s.Expires)
+		}
+		active = append(active, activeSuppression{
+			Location:    s.File,
+			Kind:        "suppression",
+			Description: fmt.Sprintf("%s (%s)", s.Reason, strings.Join(details, ", ")),
+		})
+	}
+	return active
+}
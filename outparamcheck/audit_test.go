@@ -0,0 +1,90 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveSuppressions(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "lib.go"), []byte(`package lib
+
+import "encoding/json"
+
+func Decode(data []byte, m map[string]string) {
+	json.Unmarshal(data, m) //nolint:outparamcheck // m is a map
+
+	//outparamcheck:ignore decoded into a map
+	json.Unmarshal(data, m)
+
+	//outparamcheck:ignore
+	json.Unmarshal(data, m)
+}
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "lib_test.go"), []byte(`//outparamcheck:ignore-file
+
+package lib
+`), 0644))
+
+	pkgs, err := load([]string{"./" + tmpDir}, nil, nil, testsAll, nil)
+	require.NoError(t, err)
+
+	b := baseline{
+		Version: baselineVersion,
+		Findings: []baselineFinding{
+			{File: "legacy/config.go", Line: "json.Unmarshal(data, cfg)", Method: "Unmarshal", Argument: 1},
+		},
+	}
+	allowlistPath := filepath.Join(tmpDir, "allowlist.txt")
+	require.NoError(t, ioutil.WriteFile(allowlistPath, []byte(`# generated
+0123456789abcdef  # legacy/config.go: json.Unmarshal(data, cfg)
+
+fedcba9876543210
+`), 0644))
+	entries, err := readAllowlistEntries(allowlistPath)
+	require.NoError(t, err)
+	suppressions := []suppression{
+		{File: "api/*.go", Method: "Decode", Owner: "api", Reason: "decodes into maps"},
+		{File: "legacy/*.go", Owner: "platform", Reason: "rewrite in progress", Expires: "2026-06-01"},
+		{File: "old/*.go", Owner: "platform", Reason: "deleted soon", Expires: "2026-05-01"},
+	}
+	for i := range suppressions {
+		require.Empty(t, suppressions[i].validate())
+	}
+
+	defer func(orig func() time.Time) {
+		now = orig
+	}(now)
+	now = func() time.Time {
+		return time.Date(2026, 5, 15, 0, 0, 0, 0, time.Local)
+	}
+
+	dir := filepath.ToSlash(filepath.Clean(tmpDir))
+	var got []string
+	for _, s := range activeSuppressions(pkgs, b, allowlistPath, entries, suppressions) {
+		got = append(got, s.String())
+	}
+	assert.Equal(t, []string{
+		dir + "/lib.go:6:26: nolint: m is a map",
+		dir + "/lib.go:8:2: ignore: decoded into a map",
+		dir + "/lib_test.go:1:1: ignore-file: no reason given",
+		`legacy/config.go: baseline: argument 1 to Unmarshal in "json.Unmarshal(data, cfg)"`,
+		dir + "/allowlist.txt:2: allowlist: 0123456789abcdef (legacy/config.go: json.Unmarshal(data, cfg))",
+		dir + "/allowlist.txt:4: allowlist: fedcba9876543210",
+		"api/*.go: suppression: decodes into maps (method Decode, owner api)",
+		"legacy/*.go: suppression: rewrite in progress (owner platform, expires 2026-06-01)",
+	}, got)
+}
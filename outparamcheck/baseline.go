@@ -0,0 +1,128 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// baselineVersion is the version of the baseline file format.
+const baselineVersion = 1
+
+// baseline is a snapshot of the errors reported for a codebase. Errors that are in the baseline are not reported, which
+// makes it possible to adopt the check for a codebase that has existing errors and only fail on new ones.
+type baseline struct {
+	Version  int               `json:"version"`
+	Findings []baselineFinding `json:"findings"`
+}
+
+// baselineFinding identifies an error in a baseline. Findings are identified by the file, the source line and the
+// argument rather than by line number so that they continue to match when the lines of a file are moved. A baseline
+// contains a finding once for every error that it identifies.
+type baselineFinding struct {
+	// File is the path of the file relative to the working directory, which uses forward slashes.
+	File string `json:"file"`
+	// Line is the trimmed source line on which the error is reported.
+	Line     string `json:"line"`
+	Method   string `json:"method"`
+	Argument int    `json:"argument"`
+	// Check is the name of the subcheck that reported the error, which is empty for the errors of output parameters.
+	Check string `json:"check,omitempty"`
+}
+
+// newBaseline returns the baseline of the provided errors.
+func newBaseline(errs []OutParamError) baseline {
+	b := baseline{
+		Version:  baselineVersion,
+		Findings: make([]baselineFinding, 0, len(errs)),
+	}
+	for _, err := range errs {
+		b.Findings = append(b.Findings, newBaselineFinding(err))
+	}
+	sort.Slice(b.Findings, func(i, j int) bool {
+		fi, fj := b.Findings[i], b.Findings[j]
+		if fi.File != fj.File {
+			return fi.File < fj.File
+		}
+		if fi.Line != fj.Line {
+			return fi.Line < fj.Line
+		}
+		if fi.Method != fj.Method {
+			return fi.Method < fj.Method
+		}
+		if fi.Argument != fj.Argument {
+			return fi.Argument < fj.Argument
+		}
+		return fi.Check < fj.Check
+	})
+	return b
+}
+
+func newBaselineFinding(err OutParamError) baselineFinding {
+	file := err.Pos.Filename
+	if wd, wdErr := os.Getwd(); wdErr == nil {
+		if rel, relErr := filepath.Rel(wd, file); relErr == nil {
+			file = rel
+		}
+	}
+	return baselineFinding{
+		File:     filepath.ToSlash(file),
+		Line:     err.Line,
+		Method:   err.Method,
+		Argument: err.Argument,
+		Check:    err.Check,
+	}
+}
+
+// filter returns the provided errors that are not in the baseline. Every finding of the baseline matches at most one
+// error.
+func (b baseline) filter(errs []OutParamError) []OutParamError {
+	remaining := make(map[baselineFinding]int)
+	for _, finding := range b.Findings {
+		remaining[finding]++
+	}
+	var filtered []OutParamError
+	for _, err := range errs {
+		finding := newBaselineFinding(err)
+		if remaining[finding] > 0 {
+			remaining[finding]--
+			continue
+		}
+		filtered = append(filtered, err)
+	}
+	return filtered
+}
+
+func readBaseline(path string) (baseline, error) {
+	baselineBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return baseline{}, errors.Wrapf(err, "failed to read baseline %s", path)
+	}
+	var b baseline
+	if err := json.Unmarshal(baselineBytes, &b); err != nil {
+		return baseline{}, errors.Wrapf(err, "failed to parse baseline %s", path)
+	}
+	if b.Version != baselineVersion {
+		return baseline{}, errors.Errorf("baseline %s has unsupported version %d", path, b.Version)
+	}
+	return b, nil
+}
+
+func writeBaseline(path string, b baseline) error {
+	baselineBytes, err := json.MarshalIndent(b, "", "    ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal baseline")
+	}
+	if err := ioutil.WriteFile(path, append(baselineBytes, '\n'), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write baseline %s", path)
+	}
+	return nil
+}
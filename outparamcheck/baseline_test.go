@@ -0,0 +1,70 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	absDir, err := filepath.Abs(tmpDir)
+	require.NoError(t, err)
+	newErr := func(line int, text string) OutParamError {
+		return OutParamError{
+			Pos:      token.Position{Filename: filepath.Join(absDir, "main.go"), Line: line},
+			Line:     text,
+			Method:   "Unmarshal",
+			Argument: 1,
+		}
+	}
+	baselinePath := path.Join(tmpDir, "baseline.json")
+	require.NoError(t, writeBaseline(baselinePath, newBaseline([]OutParamError{
+		newErr(10, "json.Unmarshal(data, x)"),
+		newErr(12, "json.Unmarshal(data, x)"),
+		newErr(14, "json.Unmarshal(data, y)"),
+	})))
+
+	baselineBytes, err := ioutil.ReadFile(baselinePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(baselineBytes), `"file": "`+filepath.ToSlash(path.Join(tmpDir, "main.go"))+`"`)
+
+	b, err := readBaseline(baselinePath)
+	require.NoError(t, err)
+	// the lines have moved and a third call with x has been added
+	errs := []OutParamError{
+		newErr(20, "json.Unmarshal(data, x)"),
+		newErr(22, "json.Unmarshal(data, x)"),
+		newErr(24, "json.Unmarshal(data, x)"),
+		newErr(26, "json.Unmarshal(data, y)"),
+		newErr(28, "json.Unmarshal(data, z)"),
+	}
+	assert.Equal(t, []OutParamError{errs[2], errs[4]}, b.filter(errs))
+}
+
+func TestReadBaselineErrors(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	baselinePath := path.Join(tmpDir, "baseline.json")
+	require.NoError(t, ioutil.WriteFile(baselinePath, []byte(`{"version": 2, "findings": []}`), 0644))
+	_, err = readBaseline(baselinePath)
+	assert.EqualError(t, err, "baseline "+baselinePath+" has unsupported version 2")
+
+	_, err = readBaseline(path.Join(tmpDir, "missing.json"))
+	assert.Error(t, err)
+}
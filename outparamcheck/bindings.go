@@ -0,0 +1,104 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// boundFunc returns the expression that refers to the function that the provided variable is bound to, or nil if the
+// variable is not bound to a function. A variable is bound to a function if it is assigned exactly once, the value that
+// it is assigned is a function or another variable that is referred to by name (such as in "u := json.Unmarshal") and
+// its address is never taken, so every call through the variable is a call of the function.
+func (v *visitor) boundFunc(obj *types.Var) ast.Expr {
+	if v.bindings == nil {
+		v.bindings = v.funcBindings()
+	}
+	return v.bindings[obj]
+}
+
+// funcBindings returns the variables of the checked package that are bound to a function along with the expressions
+// that refer to the functions.
+func (v *visitor) funcBindings() map[*types.Var]ast.Expr {
+	bindings := make(map[*types.Var]ast.Expr)
+	assignments := make(map[*types.Var]int)
+	addressed := make(map[*types.Var]bool)
+	assign := func(lhs, rhs ast.Expr) {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok {
+			return
+		}
+		obj, ok := v.pkg.TypesInfo.ObjectOf(ident).(*types.Var)
+		if !ok {
+			return
+		}
+		assignments[obj]++
+		if rhs != nil && v.isFuncRef(rhs) {
+			bindings[obj] = rhs
+		}
+	}
+	assignAll := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for i, expr := range lhs {
+			var value ast.Expr
+			if len(lhs) == len(rhs) {
+				value = rhs[i]
+			}
+			assign(expr, value)
+		}
+	}
+	for _, file := range v.pkg.Syntax {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				assignAll(node.Lhs, node.Rhs)
+			case *ast.ValueSpec:
+				if len(node.Values) > 0 {
+					lhs := make([]ast.Expr, len(node.Names))
+					for i, name := range node.Names {
+						lhs[i] = name
+					}
+					assignAll(lhs, node.Values)
+				}
+			case *ast.RangeStmt:
+				assignAll([]ast.Expr{node.Key, node.Value}, nil)
+			case *ast.UnaryExpr:
+				if ident, ok := node.X.(*ast.Ident); ok && node.Op == token.AND {
+					// the variable may be assigned through the pointer
+					if obj, ok := v.pkg.TypesInfo.ObjectOf(ident).(*types.Var); ok {
+						addressed[obj] = true
+					}
+				}
+			}
+			return true
+		})
+	}
+	for obj := range bindings {
+		if assignments[obj] != 1 || addressed[obj] {
+			delete(bindings, obj)
+		}
+	}
+	return bindings
+}
+
+// isFuncRef returns true if the provided expression refers by name to a function, a method value (such as dec.Decode),
+// a method expression (such as (*json.Decoder).Decode) or a variable of a function type.
+func (v *visitor) isFuncRef(expr ast.Expr) bool {
+	switch expr := v.unwrapped(expr).(type) {
+	case *ast.Ident:
+		switch obj := v.pkg.TypesInfo.Uses[expr].(type) {
+		case *types.Func:
+			return true
+		case *types.Var:
+			_, isFunc := obj.Type().Underlying().(*types.Signature)
+			return isFunc
+		}
+	case *ast.SelectorExpr:
+		_, ok := v.pkg.TypesInfo.Uses[expr.Sel].(*types.Func)
+		return ok
+	}
+	return false
+}
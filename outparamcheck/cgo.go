@@ -0,0 +1,29 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// isCgoGenerated returns true if the provided file of the provided package is generated by cgo and does not correspond
+// to a source file of the package, such as _cgo_gotypes.go. The source files of packages that use cgo are checked in
+// their cgo-processed form, whose line directives map their positions back to the source files.
+func isCgoGenerated(pkg *packages.Package, file *ast.File) bool {
+	if len(pkg.CompiledGoFiles) == len(pkg.GoFiles) {
+		return false
+	}
+	for _, adjusted := range []bool{false, true} {
+		filename := pkg.Fset.PositionFor(file.Pos(), adjusted).Filename
+		for _, goFile := range pkg.GoFiles {
+			if filename == goFile {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/build"
+	"go/token"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutParamCheckCgo(t *testing.T) {
+	if !build.Default.CgoEnabled {
+		t.Skip("cgo is not enabled")
+	}
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `package main
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"encoding/json"
+)
+
+func main() {
+	var x interface{}
+	_ = C.abs(-1)
+	_ = json.Unmarshal(nil, x)
+}
+`)
+	require.Len(t, pkgs, 1)
+	require.Empty(t, pkgs[0].Errors)
+	cfg := argsConfig(map[string][]int{
+		"encoding/json.Unmarshal": {1},
+		// called by the file that cgo generates
+		pkgs[0].PkgPath + "._cgo_runtime_cgocall": {1},
+	})
+	errs := run(pkgs, cfg)
+	for i := range errs {
+		// offsets are those of the file that cgo generates, which depend on the path of the temporary directory
+		errs[i].Pos.Offset = 0
+	}
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Line: 13, Column: 26}, Line: "_ = json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+	}, errs)
+}
@@ -0,0 +1,88 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The names of the optional subchecks, which report problems that are related to output parameters in addition to the
+// errors of the output parameters themselves. Subchecks are only run if they are enabled.
+const (
+	// CheckUnusedAssignment reports the values that are assigned to variables but never read before the variables are
+	// assigned again or the function returns.
+	CheckUnusedAssignment = "unused-assignment"
+	// CheckUncheckedError reports the calls of functions with output parameters whose error result is discarded.
+	CheckUncheckedError = "unchecked-error"
+	// CheckPointerToInterface reports the output parameters that are passed the address of a variable of an interface
+	// type.
+	CheckPointerToInterface = "pointer-to-interface"
+	// CheckNoExportedFields reports the output parameters that point to a struct without exported fields.
+	CheckNoExportedFields = "no-exported-fields"
+	// CheckUnreadOutput reports the output parameters that are passed the address of a local variable that is never
+	// read after the call.
+	CheckUnreadOutput = "unread-output"
+	// CheckAsyncLoopVar reports the asynchronous calls whose output parameters are passed the address of a loop
+	// variable that is shared by all iterations.
+	CheckAsyncLoopVar = "async-loop-var"
+)
+
+// subcheck reports the problems that it finds in the provided file of the checked package.
+type subcheck func(v *visitor, file *ast.File)
+
+var subchecks = map[string]subcheck{
+	CheckUnusedAssignment:   (*visitor).checkUnusedAssignments,
+	CheckUncheckedError:     (*visitor).checkUncheckedErrors,
+	CheckPointerToInterface: (*visitor).checkPointersToInterfaces,
+	CheckNoExportedFields:   (*visitor).checkNoExportedFields,
+	CheckUnreadOutput:       (*visitor).checkUnreadOutputs,
+	CheckAsyncLoopVar:       (*visitor).checkAsyncLoopVars,
+}
+
+// Checks returns the names of the optional subchecks in sorted order.
+func Checks() []string {
+	var names []string
+	for name := range subchecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateChecks returns the set of the subchecks with the provided names, or an error if any of the names is not the
+// name of a subcheck.
+func validateChecks(names []string) (map[string]bool, error) {
+	checks := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := subchecks[name]; !ok {
+			return nil, errors.Errorf("invalid check %q: must be one of %s", name, strings.Join(Checks(), ", "))
+		}
+		checks[name] = true
+	}
+	return checks, nil
+}
+
+// runSubchecks runs the enabled subchecks on the provided file of the checked package in the order of their names.
+func (v *visitor) runSubchecks(file *ast.File) {
+	for _, name := range Checks() {
+		if v.checks[name] {
+			subchecks[name](v, file)
+		}
+	}
+}
+
+// subcheckErrorAt reports an error of the subcheck with the provided name at the provided position.
+func (v *visitor) subcheckErrorAt(pos token.Pos, check string, severity Severity, problem string) {
+	v.report(pos, OutParamError{
+		Severity: severity,
+		Problem:  problem,
+		Check:    check,
+	})
+}
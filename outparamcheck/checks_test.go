@@ -0,0 +1,44 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+// runChecks checks the provided packages using the provided configuration and runs the subchecks with the provided
+// names.
+func runChecks(t *testing.T, pkgs []*packages.Package, cfg Config, checks ...string) []OutParamError {
+	enabled, err := validateChecks(checks)
+	require.NoError(t, err)
+	return runWithConfigs(pkgs, func(*packages.Package) Config {
+		return cfg
+	}, nil, analysis{checks: enabled})
+}
+
+func TestValidateChecks(t *testing.T) {
+	checks, err := validateChecks([]string{CheckUnusedAssignment})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{CheckUnusedAssignment: true}, checks)
+
+	_, err = validateChecks([]string{"unknown"})
+	assert.EqualError(t, err, `invalid check "unknown": must be one of async-loop-var, no-exported-fields, pointer-to-interface, unchecked-error, unread-output, unused-assignment`)
+}
+
+func TestSubcheckError(t *testing.T) {
+	err := OutParamError{
+		Pos:      token.Position{Filename: "main.go", Line: 3, Column: 2},
+		Line:     "x := 1 // comment",
+		Severity: SeverityWarning,
+		Problem:  "value assigned to x is never used",
+		Check:    CheckUnusedAssignment,
+	}
+	assert.Equal(t, "main.go:3:2\tx := 1  // warning: value assigned to x is never used (unused-assignment)", err.Error())
+}
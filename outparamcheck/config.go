@@ -4,13 +4,852 @@
 
 package outparamcheck
 
-// Config stores a map from function name to the argument indices which are output parameters.
-type Config map[string][]int
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
-var defaultCfg = Config(
+	"github.com/pkg/errors"
+)
+
+// ConfigFileName is the name of the configuration files that are applied to the packages in the directory that
+// contains the file and in its subdirectories.
+const ConfigFileName = ".outparamcheck.json"
+
+// Config stores the checks to perform.
+type Config struct {
+	// Rules is a map from function name to the rule that specifies its output parameters. A nil rule specifies that
+	// the rule for the function should be removed when the configuration is merged with another one.
+	Rules map[string]*Rule
+	// ExcludePackages are the patterns of the import paths of the packages that are not checked.
+	ExcludePackages []string
+	// Extends are the configurations that the configuration is applied on top of. Each entry is either the path of a
+	// configuration file (or of a directory that contains a configuration file) relative to the configuration that
+	// contains it or "module:" followed by a path within a Go module. Extends is empty for configurations whose
+	// extended configurations have been resolved.
+	Extends []string
+	// Match is the mode used to match the rules that do not specify a matching mode against called functions. If it
+	// is MatchDefault, such rules use MatchSuffix.
+	Match MatchMode
+	// Signatures are the rules that specify the output parameters of functions based on their signature.
+	Signatures []*SignatureRule
+}
+
+// Rule specifies the output parameters of a function and how they are checked.
+type Rule struct {
+	// Args are the arguments which are output parameters.
+	Args []Arg `json:"args,omitempty"`
+	// Severity is the severity of the errors reported by the rule.
+	Severity Severity `json:"severity,omitempty"`
+	// Match is the mode used to match the rule against called functions. If it is MatchDefault, the matching mode of
+	// the configuration is used.
+	Match MatchMode `json:"match,omitempty"`
+	// Packages are the patterns of the import paths of the packages whose functions the rule applies to. If it is
+	// empty, the rule applies to the functions of all packages.
+	Packages []string `json:"packages,omitempty"`
+	// UnmarshalLike is true if the name of the rule is the import path of a package whose exported functions and
+	// methods are all decode-style functions, in which case every argument whose parameter is an empty interface is an
+	// output parameter in addition to Args.
+	UnmarshalLike bool `json:"unmarshalLike,omitempty"`
+	// AllowNil specifies whether the output parameters may be passed the literal nil. If it is nil, nil is allowed,
+	// which suits functions that treat a nil output parameter as a request to discard the decoded value.
+	AllowNil *bool `json:"allowNil,omitempty"`
+	// Exclude is true for exclusion rules, whose names are prefixed by "!". The calls matched by an exclusion rule
+	// are not checked by any of the other rules, which makes it possible to suppress the false positives of rules that
+	// match several functions. Exclusion rules are specified using the value true.
+	Exclude bool `json:"-"`
+}
+
+// exclusionPrefix is the prefix of the names of exclusion rules.
+const exclusionPrefix = "!"
+
+// MarshalJSON marshals exclusion rules as true and other rules as objects.
+func (r Rule) MarshalJSON() ([]byte, error) {
+	if r.Exclude {
+		return json.Marshal(true)
+	}
+	type ruleAlias Rule
+	return json.Marshal(ruleAlias(r))
+}
+
+// allowsNil returns true if the output parameters of the functions matched by the rule may be passed the literal nil.
+func (r *Rule) allowsNil() bool {
+	return r.AllowNil == nil || *r.AllowNil
+}
+
+// outArgs returns the arguments that are output parameters of the functions matched by the rule.
+func (r *Rule) outArgs() []Arg {
+	if r.UnmarshalLike {
+		return append(append([]Arg(nil), r.Args...), Arg{EmptyInterfaces: true})
+	}
+	return r.Args
+}
+
+// UnmarshalJSON unmarshals a rule from either an object, an array of arguments, which is shorthand for a rule with
+// the default severity and matching mode, "any", which is shorthand for such a rule whose arguments are ["any"], or
+// true, which specifies an exclusion rule.
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var exclude bool
+	if err := json.Unmarshal(data, &exclude); err == nil && exclude {
+		*r = Rule{Exclude: true}
+		return nil
+	}
+	var args []Arg
+	if err := json.Unmarshal(data, &args); err == nil {
+		*r = Rule{Args: args}
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil && str == emptyInterfaceArgs {
+		*r = Rule{Args: []Arg{{EmptyInterfaces: true}}}
+		return nil
+	}
+	type ruleAlias Rule
+	var rule ruleAlias
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return err
+	}
+	*r = Rule(rule)
+	return nil
+}
+
+// Severity is the severity of the errors reported by a rule. Errors with SeverityWarning are reported but do not cause
+// the check to fail.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+var severityNames = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+}
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return marshalEnum(s, severityNames)
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	return unmarshalEnum(text, s, severityNames)
+}
+
+// MatchMode is the mode used to match a rule against the name of a called function.
+type MatchMode int
+
+const (
+	// MatchDefault specifies that the matching mode of the enclosing configuration should be used.
+	MatchDefault MatchMode = iota
+	// MatchSuffix matches the functions whose name ends with the name of the rule, which means that rules also apply
+	// to vendored copies of packages.
+	MatchSuffix
+	// MatchExact matches the functions whose fully qualified name is the name of the rule. Vendored copies of
+	// packages are matched using the import path of the package that was vendored.
+	MatchExact
+	// MatchRegex matches the functions whose fully qualified name matches the name of the rule interpreted as a
+	// regular expression. The regular expression must match the entire name, which is matched in the same manner as
+	// for MatchExact. MatchRegex can only be specified by individual rules.
+	MatchRegex
+)
+
+var matchModeNames = map[MatchMode]string{
+	MatchSuffix: "suffix",
+	MatchExact:  "exact",
+	MatchRegex:  "regex",
+}
+
+func (m MatchMode) String() string {
+	return matchModeNames[m]
+}
+
+func (m MatchMode) MarshalText() ([]byte, error) {
+	return marshalEnum(m, matchModeNames)
+}
+
+func (m *MatchMode) UnmarshalText(text []byte) error {
+	return unmarshalEnum(text, m, matchModeNames)
+}
+
+func marshalEnum[T comparable](val T, names map[T]string) ([]byte, error) {
+	name, ok := names[val]
+	if !ok {
+		return nil, errors.Errorf("unknown value %v", val)
+	}
+	return []byte(name), nil
+}
+
+func unmarshalEnum[T comparable](text []byte, val *T, names map[T]string) error {
+	for curr, name := range names {
+		if name == string(text) {
+			*val = curr
+			return nil
+		}
+	}
+	return errors.Errorf("unknown value %q", text)
+}
+
+// argsConfig returns a configuration that consists of rules with the default severity and matching mode for the
+// provided map from function name to argument indices.
+func argsConfig(args map[string][]int) Config {
+	cfg := Config{
+		Rules: make(map[string]*Rule, len(args)),
+	}
+	for key, val := range args {
+		cfg.Rules[key] = &Rule{Args: indexArgs(val...)}
+	}
+	return cfg
+}
+
+var defaultCfg = argsConfig(
 	map[string][]int{
-		"encoding/json.Unmarshal":     {1},
-		"encoding/safejson.Unmarshal": {1},
-		"gopkg.in/yaml.v2.Unmarshal":  {1},
+		"encoding/asn1.Unmarshal":                                                {1},
+		"encoding/asn1.UnmarshalWithParams":                                      {1},
+		"encoding/gob.Decoder.Decode":                                            {0},
+		"encoding/gob.Decoder.DecodeValue":                                       {0},
+		"encoding/json.Decoder.Decode":                                           {0},
+		"encoding/json.Unmarshal":                                                {1},
+		"encoding/safejson.Unmarshal":                                            {1},
+		"encoding/xml.Decoder.Decode":                                            {0},
+		"encoding/xml.Decoder.DecodeElement":                                     {0},
+		"encoding/xml.Unmarshal":                                                 {1},
+		"github.com/BurntSushi/toml.Decode":                                      {1},
+		"github.com/BurntSushi/toml.MetaData.PrimitiveDecode":                    {1},
+		"github.com/BurntSushi/toml.Unmarshal":                                   {1},
+		"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue.Unmarshal": {1},
+		"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue.UnmarshalListOfMaps": {1},
+		"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue.UnmarshalMap":        {1},
+		"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute.Unmarshal":           {1},
+		"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute.UnmarshalListOfMaps": {1},
+		"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute.UnmarshalMap":        {1},
+		"github.com/fxamacker/cbor.Decoder.Decode":                                         {0},
+		"github.com/fxamacker/cbor.Unmarshal":                                              {1},
+		"github.com/go-redis/redis.MapStringStringCmd.Scan":                                {0},
+		"github.com/go-redis/redis.SliceCmd.Scan":                                          {0},
+		"github.com/go-redis/redis.StringCmd.Scan":                                         {0},
+		"github.com/gomodule/redigo/redis.ScanSlice":                                       {1},
+		"github.com/gomodule/redigo/redis.ScanStruct":                                      {1},
+		"github.com/hashicorp/hcl.Decode":                                                  {0},
+		"github.com/hashicorp/hcl.DecodeObject":                                            {0},
+		"github.com/hashicorp/hcl.Unmarshal":                                               {1},
+		"github.com/hashicorp/hcl/v2/gohcl.DecodeBody":                                     {2},
+		"github.com/hashicorp/hcl/v2/gohcl.DecodeExpression":                               {2},
+		"github.com/hashicorp/hcl/v2/hclsimple.Decode":                                     {3},
+		"github.com/hashicorp/hcl/v2/hclsimple.DecodeFile":                                 {2},
+		"github.com/mitchellh/mapstructure.Decode":                                         {1},
+		"github.com/mitchellh/mapstructure.DecodeMetadata":                                 {1},
+		"github.com/mitchellh/mapstructure.WeakDecode":                                     {1},
+		"github.com/mitchellh/mapstructure.WeakDecodeMetadata":                             {1},
+		"github.com/redis/go-redis.MapStringStringCmd.Scan":                                {0},
+		"github.com/redis/go-redis.SliceCmd.Scan":                                          {0},
+		"github.com/redis/go-redis.StringCmd.Scan":                                         {0},
+		"github.com/spf13/viper.Unmarshal":                                                 {0},
+		"github.com/spf13/viper.UnmarshalExact":                                            {0},
+		"github.com/spf13/viper.UnmarshalKey":                                              {1},
+		"github.com/spf13/viper.Viper.Unmarshal":                                           {0},
+		"github.com/spf13/viper.Viper.UnmarshalExact":                                      {0},
+		"github.com/spf13/viper.Viper.UnmarshalKey":                                        {1},
+		"go.mongodb.org/mongo-driver/bson.Unmarshal":                                       {1},
+		"go.mongodb.org/mongo-driver/bson.UnmarshalExtJSON":                                {2},
+		"go.mongodb.org/mongo-driver/mongo.Cursor.All":                                     {1},
+		"go.mongodb.org/mongo-driver/mongo.Cursor.Decode":                                  {0},
+		"go.mongodb.org/mongo-driver/mongo.SingleResult.Decode":                            {0},
+		"gopkg.in/yaml.v2.Decoder.Decode":                                                  {0},
+		"gopkg.in/yaml.v2.Unmarshal":                                                       {1},
+		"gopkg.in/yaml.v3.Decoder.Decode":                                                  {0},
+		"gopkg.in/yaml.v3.Unmarshal":                                                       {1},
+		"net/rpc.Client.Call":                                                              {2},
+		"net/rpc.Client.Go":                                                                {2},
+	},
+).Merge(Config{
+	Rules: map[string]*Rule{
+		// errors.As panics if its target is nil
+		"errors.As": {Args: indexArgs(1), AllowNil: new(bool)},
+		// the arguments of the fmt scanning functions that follow the source and the format are all output parameters
+		"fmt.Fscan":   {Args: []Arg{{Index: 1, Variadic: true}}},
+		"fmt.Fscanf":  {Args: []Arg{{Index: 2, Variadic: true}}},
+		"fmt.Fscanln": {Args: []Arg{{Index: 1, Variadic: true}}},
+		"fmt.Scan":    {Args: []Arg{{Index: 0, Variadic: true}}},
+		"fmt.Scanf":   {Args: []Arg{{Index: 1, Variadic: true}}},
+		"fmt.Scanln":  {Args: []Arg{{Index: 0, Variadic: true}}},
+		"fmt.Sscan":   {Args: []Arg{{Index: 1, Variadic: true}}},
+		"fmt.Sscanf":  {Args: []Arg{{Index: 2, Variadic: true}}},
+		"fmt.Sscanln": {Args: []Arg{{Index: 1, Variadic: true}}},
+		// envconfig.Process fails unless the specification is a pointer to a struct
+		"github.com/kelseyhightower/envconfig.MustProcess": {Args: []Arg{{Index: 1, Kind: ArgKindPtrToStruct}}},
+		"github.com/kelseyhightower/envconfig.Process":     {Args: []Arg{{Index: 1, Kind: ArgKindPtrToStruct}}},
+		// the protocol buffer unmarshal functions panic if the message is nil
+		"github.com/golang/protobuf/jsonpb.Unmarshal":                              {Args: indexArgs(1), AllowNil: new(bool)},
+		"github.com/golang/protobuf/jsonpb.UnmarshalString":                        {Args: indexArgs(1), AllowNil: new(bool)},
+		"github.com/golang/protobuf/jsonpb.Unmarshaler.Unmarshal":                  {Args: indexArgs(1), AllowNil: new(bool)},
+		"github.com/golang/protobuf/proto.Unmarshal":                               {Args: indexArgs(1), AllowNil: new(bool)},
+		"google.golang.org/protobuf/encoding/protojson.Unmarshal":                  {Args: indexArgs(1), AllowNil: new(bool)},
+		"google.golang.org/protobuf/encoding/protojson.UnmarshalOptions.Unmarshal": {Args: indexArgs(1), AllowNil: new(bool)},
+		"google.golang.org/protobuf/encoding/prototext.Unmarshal":                  {Args: indexArgs(1), AllowNil: new(bool)},
+		"google.golang.org/protobuf/encoding/prototext.UnmarshalOptions.Unmarshal": {Args: indexArgs(1), AllowNil: new(bool)},
+		"google.golang.org/protobuf/proto.Unmarshal":                               {Args: indexArgs(1), AllowNil: new(bool)},
+		"google.golang.org/protobuf/proto.UnmarshalOptions.Unmarshal":              {Args: indexArgs(1), AllowNil: new(bool)},
+		// the values that redigo's redis.Scan copies the reply into follow the reply
+		"github.com/gomodule/redigo/redis.Scan": {Args: []Arg{{Index: 1, Variadic: true}}},
+		// msgpack.Unmarshal and msgpack.Decoder.Decode are variadic in v4 and earlier
+		"github.com/vmihailenco/msgpack.Decoder.Decode": {Args: []Arg{{Index: 0, Variadic: true}}},
+		"github.com/vmihailenco/msgpack.Unmarshal":      {Args: []Arg{{Index: 1, Variadic: true}}},
 	},
+})
+
+// DefaultPreset is the name of the preset that is used when no preset is specified.
+const DefaultPreset = "default"
+
+// presets stores the named built-in configurations. The built-in configuration of a run is provided by a preset.
+var presets = map[string]Config{
+	// minimal checks only the most common decode function
+	"minimal": argsConfig(map[string][]int{
+		"encoding/json.Unmarshal": {1},
+	}),
+	DefaultPreset: defaultCfg,
+	// strict checks the functions of the default configuration and does not allow nil to be passed as the output
+	// parameter of any rule
+	"strict": strictCfg(),
+	// stdlib checks only the functions of the standard library that the default configuration checks, which are matched
+	// exactly so that the rules do not apply to packages with the same name outside of the standard library
+	"stdlib": stdlibCfg(),
+}
+
+// stdlibPkgs are the packages of the standard library whose functions are checked by the default configuration.
+var stdlibPkgs = map[string]bool{
+	"encoding/asn1": true,
+	"encoding/gob":  true,
+	"encoding/json": true,
+	"encoding/xml":  true,
+	"errors":        true,
+	"fmt":           true,
+	"net/rpc":       true,
+}
+
+// stdlibCfg returns the rules of the default configuration for the functions of the packages of the standard library,
+// each of which uses MatchExact.
+func stdlibCfg() Config {
+	cfg := Config{
+		Rules: make(map[string]*Rule),
+	}
+	for key, rule := range defaultCfg.Rules {
+		// the import path of the package is followed by the first "." after its last "/"
+		lastSlash := strings.LastIndex(key, "/")
+		if !stdlibPkgs[key[:lastSlash+1+strings.Index(key[lastSlash+1:], ".")]] {
+			continue
+		}
+		exact := *rule
+		exact.Match = MatchExact
+		cfg.Rules[key] = &exact
+	}
+	return cfg
+}
+
+// strictCfg returns the rules of the default configuration, none of which allow nil arguments.
+func strictCfg() Config {
+	cfg := defaultCfg.Merge(Config{})
+	for key, rule := range cfg.Rules {
+		disallowNil := *rule
+		disallowNil.AllowNil = new(bool)
+		cfg.Rules[key] = &disallowNil
+	}
+	return cfg
+}
+
+// Presets returns the names of the available presets in sorted order.
+func Presets() []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// presetCfg returns the configuration of the preset with the provided name, or of the default preset if the name is
+// empty.
+func presetCfg(name string) (Config, error) {
+	if name == "" {
+		name = DefaultPreset
+	}
+	cfg, ok := presets[name]
+	if !ok {
+		return Config{}, errors.Errorf("unknown preset %q: must be one of %s", name, strings.Join(Presets(), ", "))
+	}
+	return cfg, nil
+}
+
+// Merge returns a new configuration that consists of the rules of cfg overridden by the rules of other. Nil rules of
+// other remove the corresponding rule. The excluded packages of the configurations are combined.
+func (cfg Config) Merge(other Config) Config {
+	merged := Config{
+		Rules: make(map[string]*Rule, len(cfg.Rules)+len(other.Rules)),
+	}
+	for key, rule := range cfg.Rules {
+		if rule != nil {
+			merged.Rules[key] = rule
+		}
+	}
+	for key, rule := range other.Rules {
+		if rule == nil {
+			delete(merged.Rules, key)
+			continue
+		}
+		merged.Rules[key] = rule
+	}
+	merged.ExcludePackages = append(append(merged.ExcludePackages, cfg.ExcludePackages...), other.ExcludePackages...)
+	merged.Signatures = append(append(merged.Signatures, cfg.Signatures...), other.Signatures...)
+	merged.Match = cfg.Match
+	if other.Match != MatchDefault {
+		merged.Match = other.Match
+	}
+	return merged
+}
+
+// matchMode returns the mode used to match the provided rule of the configuration against called functions.
+func (cfg Config) matchMode(rule *Rule) MatchMode {
+	switch {
+	case rule.Match != MatchDefault:
+		return rule.Match
+	case cfg.Match != MatchDefault:
+		return cfg.Match
+	default:
+		return MatchSuffix
+	}
+}
+
+// excludesPackage returns true if the package with the provided import path is excluded from being checked.
+func (cfg Config) excludesPackage(pkgPath string) bool {
+	return matchesAnyPackagePattern(cfg.ExcludePackages, pkgPath)
+}
+
+// Normalized returns a copy of the configuration in which the argument indices of every rule and the excluded packages
+// are sorted in ascending order.
+func (cfg Config) Normalized() Config {
+	normalized := Config{
+		Rules: make(map[string]*Rule, len(cfg.Rules)),
+	}
+	for key, rule := range cfg.Rules {
+		if rule == nil {
+			normalized.Rules[key] = nil
+			continue
+		}
+		normalizedRule := *rule
+		normalizedRule.Args = append([]Arg(nil), rule.Args...)
+		sort.Slice(normalizedRule.Args, func(i, j int) bool {
+			return normalizedRule.Args[i].less(normalizedRule.Args[j])
+		})
+		normalized.Rules[key] = &normalizedRule
+	}
+	normalized.ExcludePackages = append([]string(nil), cfg.ExcludePackages...)
+	sort.Strings(normalized.ExcludePackages)
+	// the order of extended configurations is significant
+	normalized.Extends = cfg.Extends
+	normalized.Match = cfg.Match
+	normalized.Signatures = cfg.Signatures
+	return normalized
+}
+
+// MarshalJSON marshals the configuration using the latest version of the configuration format.
+func (cfg Config) MarshalJSON() ([]byte, error) {
+	rules := cfg.Rules
+	if rules == nil {
+		rules = map[string]*Rule{}
+	}
+	return json.Marshal(struct {
+		Version         int              `json:"version"`
+		Rules           map[string]*Rule `json:"rules"`
+		ExcludePackages []string         `json:"excludePackages,omitempty"`
+		Extends         []string         `json:"extends,omitempty"`
+		Match           MatchMode        `json:"match,omitempty"`
+		Signatures      []*SignatureRule `json:"signatures,omitempty"`
+	}{
+		Version:         latestConfigVersion,
+		Rules:           rules,
+		ExcludePackages: cfg.ExcludePackages,
+		Extends:         cfg.Extends,
+		Match:           cfg.Match,
+		Signatures:      cfg.Signatures,
+	})
+}
+
+// Print writes the normalized configuration to the provided writer as indented JSON with the keys in sorted order.
+func (cfg Config) Print(w io.Writer) error {
+	cfgBytes, err := json.MarshalIndent(cfg.Normalized(), "", "    ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal configuration")
+	}
+	if _, err := fmt.Fprintln(w, string(cfgBytes)); err != nil {
+		return errors.Wrapf(err, "failed to write configuration")
+	}
+	return nil
+}
+
+// MigrateConfig returns the provided configuration, which may use any supported version of the configuration format,
+// converted to the latest version of the configuration format. Comments in the provided configuration are not
+// preserved.
+func MigrateConfig(cfgJSON string) ([]byte, error) {
+	cfg, err := loadCfg(cfgJSON)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := cfg.Print(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const (
+	// versionKey is the key of the configuration format version, which is required in configurations that use version 2
+	// or later of the format. Version 1 configurations are a map from function name to argument indices, in which the
+	// version is optional.
+	versionKey = "version"
+	// rulesKey is the key of the rules in configurations that use version 2 or later of the format.
+	rulesKey = "rules"
+	// excludePackagesKey is the key of the excluded packages in configurations that use version 2 or later of the
+	// format.
+	excludePackagesKey = "excludePackages"
+	// extendsKey is the key of the extended configurations in configurations that use version 2 or later of the
+	// format.
+	extendsKey = "extends"
+	// matchKey is the key of the default matching mode in configurations that use version 2 or later of the format.
+	matchKey = "match"
+	// signaturesKey is the key of the signature rules in configurations that use version 2 or later of the format.
+	signaturesKey = "signatures"
+
+	latestConfigVersion = 2
 )
+
+// parseCfg parses the provided JSON into a Config and validates each of its entries. Comments and trailing commas are
+// permitted in the JSON. All problems found in the configuration are reported together, each prefixed by the line and
+// key of the offending entry.
+func parseCfg(cfgJSON string) (Config, error) {
+	doc := stripJSONC(cfgJSON)
+	entries, end, err := objectEntries(doc, 0)
+	if err != nil {
+		return Config{}, err
+	}
+	if strings.TrimSpace(doc[end:]) != "" {
+		return Config{}, errors.Errorf("line %d: unexpected content after configuration object", lineAt(doc, int64(end)))
+	}
+
+	version := 1
+	for _, entry := range entries {
+		if entry.key != versionKey {
+			continue
+		}
+		if err := json.Unmarshal(entry.value, &version); err != nil || version < 1 || version > latestConfigVersion {
+			return Config{}, errors.Errorf("line %d: %q: unsupported configuration version %s: must be between 1 and %d",
+				entry.line, entry.key, entry.value, latestConfigVersion)
+		}
+	}
+
+	cfg := Config{
+		Rules: make(map[string]*Rule),
+	}
+	var problems []string
+	if version == 1 {
+		// the version of a version 1 configuration is optional and is not a rule
+		var ruleEntries []jsonEntry
+		for _, entry := range entries {
+			if entry.key != versionKey {
+				ruleEntries = append(ruleEntries, entry)
+			}
+		}
+		problems = parseRules(ruleEntries, configSchema.definition("configV1"), cfg.Rules)
+	} else {
+		v2Schema := configSchema.definition("configV2")
+		for _, entry := range entries {
+			if entry.key == rulesKey && strings.HasPrefix(string(entry.value), "{") {
+				// the rules are validated individually so that problems are reported with the line of the rule
+				ruleEntries, _, err := objectEntries(doc, entry.offset)
+				if err != nil {
+					return Config{}, err
+				}
+				problems = append(problems, parseRules(ruleEntries, v2Schema.propertySchema(rulesKey), cfg.Rules)...)
+				continue
+			}
+			entryProblems := validateEntries([]jsonEntry{entry}, v2Schema)
+			if len(entryProblems) == 0 {
+				var dest interface{}
+				switch entry.key {
+				case excludePackagesKey:
+					dest = &cfg.ExcludePackages
+				case extendsKey:
+					dest = &cfg.Extends
+				case matchKey:
+					dest = &cfg.Match
+				case signaturesKey:
+					dest = &cfg.Signatures
+				}
+				if dest != nil {
+					if err := json.Unmarshal(entry.value, dest); err != nil {
+						return Config{}, errors.Wrapf(err, "line %d: %q: failed to parse value", entry.line, entry.key)
+					}
+				}
+				if entry.key == signaturesKey {
+					for i, rule := range cfg.Signatures {
+						if err := rule.validate(); err != nil {
+							entryProblems = append(entryProblems, fmt.Sprintf("line %d: %q: [%d]: %v", entry.line, entry.key, i, err))
+						}
+					}
+				}
+			}
+			problems = append(problems, entryProblems...)
+		}
+	}
+	if len(problems) > 0 {
+		return Config{}, errors.Errorf("%s:\n\t%s", plural(len(problems), "problem", "problems"), strings.Join(problems, "\n\t"))
+	}
+	cfg.compilePackagePatterns()
+	return cfg, nil
+}
+
+// compilePackagePatterns compiles the package patterns of the configuration so that they are not compiled when the
+// packages are checked.
+func (cfg Config) compilePackagePatterns() {
+	patterns := append([]string(nil), cfg.ExcludePackages...)
+	for _, rule := range cfg.Rules {
+		if rule != nil {
+			patterns = append(patterns, rule.Packages...)
+		}
+	}
+	for _, rule := range cfg.Signatures {
+		patterns = append(patterns, rule.Packages...)
+	}
+	for _, pattern := range patterns {
+		compiledPackagePattern(pattern)
+	}
+}
+
+// parseRules validates the provided entries against the schema of the object that contains them and stores the rule
+// of each valid entry in rules. Returns a description of every problem found in the entries.
+func parseRules(entries []jsonEntry, objSchema *schema, rules map[string]*Rule) []string {
+	var problems []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if seen[entry.key] {
+			problems = append(problems, fmt.Sprintf("line %d: %q: duplicate key", entry.line, entry.key))
+			continue
+		}
+		seen[entry.key] = true
+		if entryProblems := validateEntries([]jsonEntry{entry}, objSchema); len(entryProblems) > 0 {
+			problems = append(problems, entryProblems...)
+			continue
+		}
+		var rule *Rule
+		if err := json.Unmarshal(entry.value, &rule); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %q: %v", entry.line, entry.key, err))
+			continue
+		}
+		if isExclusion := strings.HasPrefix(entry.key, exclusionPrefix); rule != nil && isExclusion != rule.Exclude {
+			if isExclusion {
+				problems = append(problems, fmt.Sprintf("line %d: %q: exclusion rules must be true", entry.line, entry.key))
+			} else {
+				problems = append(problems, fmt.Sprintf("line %d: %q: only the names of exclusion rules, which must start with %q, can be true", entry.line, entry.key, exclusionPrefix))
+			}
+			continue
+		}
+		if rule != nil && len(rule.Args) == 0 && !rule.UnmarshalLike && !rule.Exclude {
+			problems = append(problems, fmt.Sprintf("line %d: %q: must contain %q", entry.line, entry.key, "args"))
+			continue
+		}
+		if rule != nil && rule.Match == MatchRegex {
+			if _, err := regexp.Compile(entry.key); err != nil {
+				problems = append(problems, fmt.Sprintf("line %d: %q: invalid regular expression: %v", entry.line, entry.key, err))
+				continue
+			}
+		}
+		rules[entry.key] = rule
+	}
+	return problems
+}
+
+// validateEntries validates the keys and values of the provided entries against the schema of the object that
+// contains them. Returns a description of every problem found, each prefixed by the line and key of the entry.
+func validateEntries(entries []jsonEntry, objSchema *schema) []string {
+	var problems []string
+	for _, entry := range entries {
+		entryProblems := objSchema.validateKey(entry.key)
+		val, err := decodeJSONValue(entry.value)
+		if err != nil {
+			entryProblems = append(entryProblems, err.Error())
+		} else if valSchema := objSchema.propertySchema(entry.key); valSchema != nil {
+			entryProblems = append(entryProblems, valSchema.validate("", val)...)
+		}
+		for _, problem := range entryProblems {
+			problems = append(problems, fmt.Sprintf("line %d: %q: %s", entry.line, entry.key, problem))
+		}
+	}
+	return problems
+}
+
+// jsonEntry is a single entry of a JSON object.
+type jsonEntry struct {
+	key string
+	// line is the line of the document on which the key of the entry appears.
+	line int
+	// offset is the offset of the value of the entry in the document.
+	offset int
+	value  json.RawMessage
+}
+
+// objectEntries returns the entries of the JSON object that starts at the provided offset of the document along with
+// the offset of the end of the object.
+func objectEntries(doc string, offset int) ([]jsonEntry, int, error) {
+	dec := json.NewDecoder(strings.NewReader(doc[offset:]))
+	currOffset := func() int {
+		return offset + int(dec.InputOffset())
+	}
+	if tok, err := dec.Token(); err != nil {
+		return nil, 0, errors.Wrapf(err, "line %d: failed to parse configuration", lineAt(doc, int64(currOffset())))
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, 0, errors.Errorf("line %d: configuration must be a JSON object", lineAt(doc, int64(currOffset())))
+	}
+
+	var entries []jsonEntry
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "line %d: failed to parse configuration", lineAt(doc, int64(currOffset())))
+		}
+		key := tok.(string)
+		line := lineAt(doc, int64(currOffset()))
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, 0, errors.Wrapf(err, "line %d: %q: failed to parse value", line, key)
+		}
+		entries = append(entries, jsonEntry{
+			key:    key,
+			line:   line,
+			offset: currOffset() - len(raw),
+			value:  raw,
+		})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, 0, errors.Wrapf(err, "line %d: failed to parse configuration", lineAt(doc, int64(currOffset())))
+	}
+	return entries, currOffset(), nil
+}
+
+// lineAt returns the 1-based line number of the provided byte offset in s.
+func lineAt(s string, offset int64) int {
+	if offset > int64(len(s)) {
+		offset = int64(len(s))
+	}
+	return strings.Count(s[:offset], "\n") + 1
+}
+
+// ConfigForDir returns the configuration that applies to the packages in the provided directory, which is the provided
+// configuration with the configuration files in the working directory and in every directory between it and the
+// provided directory applied on top of it.
+func ConfigForDir(cfg Config, dir string) (Config, error) {
+	dirCfgs, err := newDirConfigs(".", cfg)
+	if err != nil {
+		return Config{}, err
+	}
+	return dirCfgs.forDir(dir)
+}
+
+// dirConfigs resolves the configuration that applies to the packages in a directory, which is the base configuration
+// with the configuration files in the root directory and in every directory between the root and the directory
+// applied on top of it (the files in deeper directories take precedence). The configuration file in the root directory
+// of a module (other than the root directory) replaces the configuration files of the directories above it, so the
+// modules of a Go workspace use their own configuration file if they have one and the configuration file of the
+// workspace otherwise.
+type dirConfigs struct {
+	root  string
+	base  Config
+	files map[string]*Config
+}
+
+func newDirConfigs(root string, base Config) (*dirConfigs, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to determine absolute path of %s", root)
+	}
+	return &dirConfigs{
+		root:  absRoot,
+		base:  base,
+		files: make(map[string]*Config),
+	}, nil
+}
+
+// forDir returns the configuration that applies to the packages in the provided directory. Directories outside of
+// the root directory use the base configuration.
+func (d *dirConfigs) forDir(dir string) (Config, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "failed to determine absolute path of %s", dir)
+	}
+	relDir, err := filepath.Rel(d.root, absDir)
+	if err != nil || relDir == ".." || strings.HasPrefix(relDir, ".."+string(filepath.Separator)) {
+		return d.base, nil
+	}
+
+	cfgDirs := []string{d.root}
+	if relDir != "." {
+		currDir := d.root
+		for _, part := range strings.Split(relDir, string(filepath.Separator)) {
+			currDir = filepath.Join(currDir, part)
+			cfgDirs = append(cfgDirs, currDir)
+		}
+	}
+
+	cfg := d.base
+	for _, currDir := range cfgDirs {
+		fileCfg, err := d.file(currDir)
+		if err != nil {
+			return Config{}, err
+		}
+		if fileCfg == nil {
+			continue
+		}
+		if currDir != d.root && isModuleRoot(currDir) {
+			// the configuration file of a module applies to the module regardless of the directory from which the
+			// module is checked, so it replaces the configuration files of the directories above the module
+			cfg = d.base
+		}
+		cfg = cfg.Merge(*fileCfg)
+	}
+	return cfg, nil
+}
+
+// isModuleRoot returns true if the provided directory is the root directory of a Go module.
+func isModuleRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
+// file returns the configuration in the configuration file in the provided directory, or nil if the directory does
+// not contain a configuration file.
+func (d *dirConfigs) file(dir string) (*Config, error) {
+	if cfg, ok := d.files[dir]; ok {
+		return cfg, nil
+	}
+	cfgPath := filepath.Join(dir, ConfigFileName)
+	var cfg *Config
+	if _, err := os.Stat(cfgPath); err == nil {
+		fileCfg, err := loadCfgFromPath(cfgPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load configuration file %s", cfgPath)
+		}
+		cfg = &fileCfg
+	} else if !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "failed to stat %s", cfgPath)
+	}
+	d.files[dir] = cfg
+	return cfg, nil
+}
@@ -0,0 +1,952 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCfg(t *testing.T) {
+	cfg, err := parseCfg(`{
+		"github.com/palantir/example/config.Load": [0],
+		"github.com/palantir/example/config.LoadAll": [1, 2]
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, argsConfig(map[string][]int{
+		"github.com/palantir/example/config.Load":    {0},
+		"github.com/palantir/example/config.LoadAll": {1, 2},
+	}), cfg)
+}
+
+func TestParseCfgExplicitVersion1(t *testing.T) {
+	cfg, err := parseCfg(`{
+		"version": 1,
+		"encoding/json.Unmarshal": [1]
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}), cfg)
+}
+
+func TestParseCfgAnyArgs(t *testing.T) {
+	cfg, err := parseCfg(`{
+		"encoding/json.Unmarshal": "any",
+		"fmt.Sscan": ["any"]
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Rules: map[string]*Rule{
+			"encoding/json.Unmarshal": {Args: []Arg{{EmptyInterfaces: true}}},
+			"fmt.Sscan":               {Args: []Arg{{EmptyInterfaces: true}}},
+		},
+	}, cfg)
+}
+
+func TestParseCfgUnmarshalLike(t *testing.T) {
+	cfg, err := parseCfg(`{
+		"version": 2,
+		"rules": {
+			"gopkg.in/yaml.v3": {"unmarshalLike": true},
+			"github.com/palantir/example/codec": {"unmarshalLike": true, "args": [0]}
+		}
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Rules: map[string]*Rule{
+			"gopkg.in/yaml.v3":                  {UnmarshalLike: true},
+			"github.com/palantir/example/codec": {Args: indexArgs(0), UnmarshalLike: true},
+		},
+	}, cfg)
+}
+
+func TestParseCfgExclusions(t *testing.T) {
+	cfg, err := parseCfg(`{
+		"json.Unmarshal": [1],
+		"!github.com/palantir/example/myjson.Unmarshal": true
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Rules: map[string]*Rule{
+			"json.Unmarshal": {Args: indexArgs(1)},
+			"!github.com/palantir/example/myjson.Unmarshal": {Exclude: true},
+		},
+	}, cfg)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, cfg.Print(buf))
+	assert.Contains(t, buf.String(), `"!github.com/palantir/example/myjson.Unmarshal": true`)
+}
+
+func TestParseCfgV2(t *testing.T) {
+	cfg, err := parseCfg(`{
+		"version": 2,
+		"rules": {
+			"github.com/palantir/example/config.Load": {"args": [0], "severity": "warning", "match": "suffix"},
+			"github.com/palantir/example/config.LoadAll": [1, 2],
+			"github.com/palantir/example/config.Scan": {"args": ["1+"]},
+			"github.com/palantir/example/config.LoadExact": {"args": [0], "match": "exact"},
+			"encoding/json.Unmarshal": null
+		},
+		"excludePackages": ["github.com/palantir/example/legacy/...", "*_generated"],
+		"match": "exact"
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Rules: map[string]*Rule{
+			"github.com/palantir/example/config.Load":      {Args: indexArgs(0), Severity: SeverityWarning, Match: MatchSuffix},
+			"github.com/palantir/example/config.LoadAll":   {Args: indexArgs(1, 2)},
+			"github.com/palantir/example/config.Scan":      {Args: []Arg{{Index: 1, Variadic: true}}},
+			"github.com/palantir/example/config.LoadExact": {Args: indexArgs(0), Match: MatchExact},
+			"encoding/json.Unmarshal":                      nil,
+		},
+		ExcludePackages: []string{"github.com/palantir/example/legacy/...", "*_generated"},
+		Match:           MatchExact,
+	}, cfg)
+}
+
+func TestParseCfgSignatures(t *testing.T) {
+	cfg, err := parseCfg(`{
+		"version": 2,
+		"signatures": [
+			{"packages": ["github.com/palantir/example/..."], "params": ["...", "&any"], "results": ["error"], "severity": "warning"}
+		]
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, []*SignatureRule{
+		{Packages: []string{"github.com/palantir/example/..."}, Params: []string{"...", "&any"}, Results: []string{"error"}, Severity: SeverityWarning},
+	}, cfg.Signatures)
+
+	_, err = parseCfg(`{
+		"version": 2,
+		"signatures": [
+			{"packages": ["github.com/palantir/example/..."], "params": ["...", "any"]}
+		]
+	}`)
+	assert.EqualError(t, err, "1 problem:\n\tline 3: \"signatures\": [0]: params must contain at least one output parameter prefixed by \"&\"")
+}
+
+func TestParseCfgWithComments(t *testing.T) {
+	cfg, err := parseCfg(`{
+		// used by the service bootstrap code
+		"github.com/palantir/example/config.Load": [0],
+		/* the second argument is the "output" */
+		"github.com/palantir/example/config.LoadAll": [1, /* and the third */ 2,],
+		"github.com/palantir/example/url.Parse": [0], // "//" in keys and comments is fine
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, argsConfig(map[string][]int{
+		"github.com/palantir/example/config.Load":    {0},
+		"github.com/palantir/example/config.LoadAll": {1, 2},
+		"github.com/palantir/example/url.Parse":      {0},
+	}), cfg)
+
+	_, err = parseCfg("{\n// comment\n\"a.B\": [-1], /* unterminated")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3: failed to parse configuration")
+}
+
+func TestStripJSONC(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{`{"a": "//not a comment", "b": "/*nor this*/"}`, `{"a": "//not a comment", "b": "/*nor this*/"}`},
+		{`{"a": "\"//", /* c */ "b": [1,]}`, `{"a": "\"//",         "b": [1 ]}`},
+		{"[1, // c\n2,\n]", "[1,     \n2 \n]"},
+		{`{"a": [1, 2], "b": {},}`, `{"a": [1, 2], "b": {} }`},
+	} {
+		assert.Equal(t, tc.expected, stripJSONC(tc.input), tc.input)
+	}
+}
+
+func TestParseCfgErrors(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "not an object",
+			input:    `[0]`,
+			expected: `line 1: configuration must be a JSON object`,
+		},
+		{
+			name:     "invalid JSON",
+			input:    "{\n\"a.B\": [0],\n\"a.C\" [0]}",
+			expected: `line 3: "a.C": failed to parse value: invalid character '[' after object key`,
+		},
+		{
+			name: "non-integer index",
+			input: `{
+				"a.B": [0],
+				"a.C": [-1.5]
+			}`,
+			expected: "1 problem:\n\tline 3: \"a.C\": [0]: must be an integer or a string or an object, was -1.5",
+		},
+		{
+			name: "duplicate index",
+			input: `{
+				"a.B": [1, 0, 1]
+			}`,
+			expected: "1 problem:\n\tline 2: \"a.B\": [2]: 1 is specified more than once",
+		},
+		{
+			name: "duplicate key",
+			input: `{
+				"a.B": [0],
+				"a.B": [1]
+			}`,
+			expected: "1 problem:\n\tline 3: \"a.B\": duplicate key",
+		},
+		{
+			name: "empty key",
+			input: `{
+				"": [0]
+			}`,
+			expected: "1 problem:\n\tline 2: \"\": key must match the pattern ^\\S(.*\\S)?$, was \"\"",
+		},
+		{
+			name: "malformed entries",
+			input: `{
+				"a.B": 1,
+				" a.C": ["1"],
+				"a.D": [],
+				"a.E": "all",
+				"!a.F": [0],
+				"a.G": true,
+				"!a.H": false
+			}`,
+			expected: "8 problems:\n" +
+				"\tline 2: \"a.B\": must be an array or a string or a boolean or null, was 1\n" +
+				"\tline 3: \" a.C\": key must match the pattern ^\\S(.*\\S)?$, was \" a.C\"\n" +
+				"\tline 3: \" a.C\": [0]: must match the pattern ^(-?[0-9]+\\+|any)$, was \"1\"\n" +
+				"\tline 4: \"a.D\": must contain at least 1 item\n" +
+				"\tline 5: \"a.E\": must be one of \"any\", was \"all\"\n" +
+				"\tline 6: \"!a.F\": exclusion rules must be true\n" +
+				"\tline 7: \"a.G\": only the names of exclusion rules, which must start with \"!\", can be true\n" +
+				"\tline 8: \"!a.H\": must be one of true, was false",
+		},
+	}
+	tcs = append(tcs, []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "unsupported version",
+			input:    `{"version": 3}`,
+			expected: `line 1: "version": unsupported configuration version 3: must be between 1 and 2`,
+		},
+		{
+			name:     "version 0",
+			input:    `{"version": 0, "a.B": [0]}`,
+			expected: `line 1: "version": unsupported configuration version 0: must be between 1 and 2`,
+		},
+		{
+			name: "v2 rules in version 1",
+			input: `{
+				"version": 1,
+				"rules": {"a.B": [0]}
+			}`,
+			expected: "1 problem:\n" +
+				"\tline 3: \"rules\": must be an array or a string or a boolean or null, was {\"a.B\":[0]}",
+		},
+		{
+			name: "invalid v2 rules",
+			input: `{
+				"version": 2,
+				"rules": {
+					"a.B": {"args": [0], "severity": "fatal"},
+					"a.C": {"severity": "error"},
+					"a.D": {"args": [1.5], "other": true},
+					"a.B": [0]
+				},
+				"excludes": []
+			}`,
+			expected: "6 problems:\n" +
+				"\tline 4: \"a.B\": [\"severity\"]: must be one of \"error\", \"warning\", was \"fatal\"\n" +
+				"\tline 5: \"a.C\": must contain \"args\"\n" +
+				"\tline 6: \"a.D\": [\"args\"][0]: must be an integer or a string or an object, was 1.5\n" +
+				"\tline 6: \"a.D\": [\"other\"]: is not allowed\n" +
+				"\tline 7: \"a.B\": duplicate key\n" +
+				"\tline 9: \"excludes\": is not allowed",
+		},
+		{
+			name: "invalid regex rule",
+			input: `{
+				"version": 2,
+				"rules": {
+					".*\\.Decode$": {"args": [0], "match": "regex", "packages": ["encoding/..."]},
+					"(.*\\.Decode": {"args": [0], "match": "regex"},
+					"a.B": {"args": [0], "packages": []}
+				},
+				"match": "regex"
+			}`,
+			expected: "3 problems:\n" +
+				"\tline 5: \"(.*\\\\.Decode\": invalid regular expression: error parsing regexp: missing closing ): `(.*\\.Decode`\n" +
+				"\tline 6: \"a.B\": [\"packages\"]: must contain at least 1 item\n" +
+				"\tline 8: \"match\": must be one of \"suffix\", \"exact\", was \"regex\"",
+		},
+	}...)
+	for _, tc := range tcs {
+		_, err := parseCfg(tc.input)
+		assert.EqualError(t, err, tc.expected, tc.name)
+	}
+}
+
+func TestConfigForDir(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir("", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	for dir, content := range map[string]string{
+		".":                 `{"example.com/config.Load": [0]}`,
+		"internal":          `{"example.com/config.LoadStrict": [1]}`,
+		"internal/legacy":   `{"example.com/config.Load": null, "encoding/json.Unmarshal": null}`,
+		"internal/override": `{"example.com/config.Load": [2]}`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, dir), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, dir, ConfigFileName), []byte(content), 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "internal", "legacy", "nested"), 0755))
+
+	dirCfgs, err := newDirConfigs(tmpDir, argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		dir      string
+		expected Config
+	}{
+		{
+			dir: ".",
+			expected: argsConfig(map[string][]int{
+				"encoding/json.Unmarshal": {1},
+				"example.com/config.Load": {0},
+			}),
+		},
+		{
+			dir: "internal",
+			expected: argsConfig(map[string][]int{
+				"encoding/json.Unmarshal":       {1},
+				"example.com/config.Load":       {0},
+				"example.com/config.LoadStrict": {1},
+			}),
+		},
+		{
+			dir: "internal/legacy/nested",
+			expected: argsConfig(map[string][]int{
+				"example.com/config.LoadStrict": {1},
+			}),
+		},
+		{
+			dir: "internal/override",
+			expected: argsConfig(map[string][]int{
+				"encoding/json.Unmarshal":       {1},
+				"example.com/config.Load":       {2},
+				"example.com/config.LoadStrict": {1},
+			}),
+		},
+		{
+			dir: "..",
+			expected: argsConfig(map[string][]int{
+				"encoding/json.Unmarshal": {1},
+			}),
+		},
+	} {
+		cfg, err := dirCfgs.forDir(filepath.Join(tmpDir, tc.dir))
+		require.NoError(t, err, tc.dir)
+		assert.Equal(t, tc.expected, cfg, tc.dir)
+	}
+}
+
+func TestConfigForDirModules(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir("", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	for file, content := range map[string]string{
+		ConfigFileName:            `{"example.com/config.Load": [0]}`,
+		"a/go.mod":                "module example.com/a\n",
+		"a/" + ConfigFileName:     `{"example.com/config.LoadModule": [1]}`,
+		"a/sub/" + ConfigFileName: `{"example.com/config.LoadSub": [2]}`,
+		"b/go.mod":                "module example.com/b\n",
+		"b/sub/" + ConfigFileName: `{"example.com/config.LoadSub": [2]}`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, file)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0644))
+	}
+
+	dirCfgs, err := newDirConfigs(tmpDir, argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		dir      string
+		expected Config
+	}{
+		{
+			// module with a configuration file does not use the configuration file of the workspace
+			dir: "a/sub",
+			expected: argsConfig(map[string][]int{
+				"encoding/json.Unmarshal":       {1},
+				"example.com/config.LoadModule": {1},
+				"example.com/config.LoadSub":    {2},
+			}),
+		},
+		{
+			// module without a configuration file falls back to the configuration file of the workspace
+			dir: "b/sub",
+			expected: argsConfig(map[string][]int{
+				"encoding/json.Unmarshal":    {1},
+				"example.com/config.Load":    {0},
+				"example.com/config.LoadSub": {2},
+			}),
+		},
+	} {
+		cfg, err := dirCfgs.forDir(filepath.Join(tmpDir, tc.dir))
+		require.NoError(t, err, tc.dir)
+		assert.Equal(t, tc.expected, cfg, tc.dir)
+	}
+}
+
+func TestConfigPrint(t *testing.T) {
+	cfg, err := LoadConfig(`{"github.com/palantir/example/config.Load": [2, 0], "encoding/json.Unmarshal": [0]}`, "")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, cfg.Print(buf))
+	assert.Equal(t, `{
+    "version": 2,
+    "rules": {
+        "encoding/asn1.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "encoding/asn1.UnmarshalWithParams": {
+            "args": [
+                1
+            ]
+        },
+        "encoding/gob.Decoder.Decode": {
+            "args": [
+                0
+            ]
+        },
+        "encoding/gob.Decoder.DecodeValue": {
+            "args": [
+                0
+            ]
+        },
+        "encoding/json.Decoder.Decode": {
+            "args": [
+                0
+            ]
+        },
+        "encoding/json.Unmarshal": {
+            "args": [
+                0
+            ]
+        },
+        "encoding/safejson.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "encoding/xml.Decoder.Decode": {
+            "args": [
+                0
+            ]
+        },
+        "encoding/xml.Decoder.DecodeElement": {
+            "args": [
+                0
+            ]
+        },
+        "encoding/xml.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "errors.As": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "fmt.Fscan": {
+            "args": [
+                "1+"
+            ]
+        },
+        "fmt.Fscanf": {
+            "args": [
+                "2+"
+            ]
+        },
+        "fmt.Fscanln": {
+            "args": [
+                "1+"
+            ]
+        },
+        "fmt.Scan": {
+            "args": [
+                "0+"
+            ]
+        },
+        "fmt.Scanf": {
+            "args": [
+                "1+"
+            ]
+        },
+        "fmt.Scanln": {
+            "args": [
+                "0+"
+            ]
+        },
+        "fmt.Sscan": {
+            "args": [
+                "1+"
+            ]
+        },
+        "fmt.Sscanf": {
+            "args": [
+                "2+"
+            ]
+        },
+        "fmt.Sscanln": {
+            "args": [
+                "1+"
+            ]
+        },
+        "github.com/BurntSushi/toml.Decode": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/BurntSushi/toml.MetaData.PrimitiveDecode": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/BurntSushi/toml.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue.UnmarshalListOfMaps": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue.UnmarshalMap": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute.UnmarshalListOfMaps": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute.UnmarshalMap": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/fxamacker/cbor.Decoder.Decode": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/fxamacker/cbor.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/go-redis/redis.MapStringStringCmd.Scan": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/go-redis/redis.SliceCmd.Scan": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/go-redis/redis.StringCmd.Scan": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/golang/protobuf/jsonpb.Unmarshal": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "github.com/golang/protobuf/jsonpb.UnmarshalString": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "github.com/golang/protobuf/jsonpb.Unmarshaler.Unmarshal": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "github.com/golang/protobuf/proto.Unmarshal": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "github.com/gomodule/redigo/redis.Scan": {
+            "args": [
+                "1+"
+            ]
+        },
+        "github.com/gomodule/redigo/redis.ScanSlice": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/gomodule/redigo/redis.ScanStruct": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/hashicorp/hcl.Decode": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/hashicorp/hcl.DecodeObject": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/hashicorp/hcl.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/hashicorp/hcl/v2/gohcl.DecodeBody": {
+            "args": [
+                2
+            ]
+        },
+        "github.com/hashicorp/hcl/v2/gohcl.DecodeExpression": {
+            "args": [
+                2
+            ]
+        },
+        "github.com/hashicorp/hcl/v2/hclsimple.Decode": {
+            "args": [
+                3
+            ]
+        },
+        "github.com/hashicorp/hcl/v2/hclsimple.DecodeFile": {
+            "args": [
+                2
+            ]
+        },
+        "github.com/kelseyhightower/envconfig.MustProcess": {
+            "args": [
+                {
+                    "index": 1,
+                    "kind": "ptr-to-struct"
+                }
+            ]
+        },
+        "github.com/kelseyhightower/envconfig.Process": {
+            "args": [
+                {
+                    "index": 1,
+                    "kind": "ptr-to-struct"
+                }
+            ]
+        },
+        "github.com/mitchellh/mapstructure.Decode": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/mitchellh/mapstructure.DecodeMetadata": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/mitchellh/mapstructure.WeakDecode": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/mitchellh/mapstructure.WeakDecodeMetadata": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/palantir/example/config.Load": {
+            "args": [
+                0,
+                2
+            ]
+        },
+        "github.com/redis/go-redis.MapStringStringCmd.Scan": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/redis/go-redis.SliceCmd.Scan": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/redis/go-redis.StringCmd.Scan": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/spf13/viper.Unmarshal": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/spf13/viper.UnmarshalExact": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/spf13/viper.UnmarshalKey": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/spf13/viper.Viper.Unmarshal": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/spf13/viper.Viper.UnmarshalExact": {
+            "args": [
+                0
+            ]
+        },
+        "github.com/spf13/viper.Viper.UnmarshalKey": {
+            "args": [
+                1
+            ]
+        },
+        "github.com/vmihailenco/msgpack.Decoder.Decode": {
+            "args": [
+                "0+"
+            ]
+        },
+        "github.com/vmihailenco/msgpack.Unmarshal": {
+            "args": [
+                "1+"
+            ]
+        },
+        "go.mongodb.org/mongo-driver/bson.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "go.mongodb.org/mongo-driver/bson.UnmarshalExtJSON": {
+            "args": [
+                2
+            ]
+        },
+        "go.mongodb.org/mongo-driver/mongo.Cursor.All": {
+            "args": [
+                1
+            ]
+        },
+        "go.mongodb.org/mongo-driver/mongo.Cursor.Decode": {
+            "args": [
+                0
+            ]
+        },
+        "go.mongodb.org/mongo-driver/mongo.SingleResult.Decode": {
+            "args": [
+                0
+            ]
+        },
+        "google.golang.org/protobuf/encoding/protojson.Unmarshal": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "google.golang.org/protobuf/encoding/protojson.UnmarshalOptions.Unmarshal": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "google.golang.org/protobuf/encoding/prototext.Unmarshal": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "google.golang.org/protobuf/encoding/prototext.UnmarshalOptions.Unmarshal": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "google.golang.org/protobuf/proto.Unmarshal": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "google.golang.org/protobuf/proto.UnmarshalOptions.Unmarshal": {
+            "args": [
+                1
+            ],
+            "allowNil": false
+        },
+        "gopkg.in/yaml.v2.Decoder.Decode": {
+            "args": [
+                0
+            ]
+        },
+        "gopkg.in/yaml.v2.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "gopkg.in/yaml.v3.Decoder.Decode": {
+            "args": [
+                0
+            ]
+        },
+        "gopkg.in/yaml.v3.Unmarshal": {
+            "args": [
+                1
+            ]
+        },
+        "net/rpc.Client.Call": {
+            "args": [
+                2
+            ]
+        },
+        "net/rpc.Client.Go": {
+            "args": [
+                2
+            ]
+        }
+    }
+}
+`, buf.String())
+}
+
+func TestLoadConfigPreset(t *testing.T) {
+	cfg, err := LoadConfig(`{"github.com/palantir/example/config.Load": [0]}`, "minimal")
+	require.NoError(t, err)
+	assert.Equal(t, argsConfig(map[string][]int{
+		"encoding/json.Unmarshal":                 {1},
+		"github.com/palantir/example/config.Load": {0},
+	}), cfg)
+
+	// the user-supplied configuration overrides and removes the rules of the preset
+	cfg, err = LoadConfig(`{"encoding/json.Unmarshal": null, "github.com/palantir/example/config.Load": [0]}`, "minimal")
+	require.NoError(t, err)
+	assert.Equal(t, argsConfig(map[string][]int{
+		"github.com/palantir/example/config.Load": {0},
+	}), cfg)
+
+	cfg, err = LoadConfig("", "stdlib")
+	require.NoError(t, err)
+	for key, rule := range cfg.Rules {
+		assert.Equal(t, MatchExact, rule.Match, key)
+	}
+	assert.Equal(t, &Rule{Args: indexArgs(0), Match: MatchExact}, cfg.Rules["encoding/json.Decoder.Decode"])
+	assert.Equal(t, &Rule{Args: []Arg{{Index: 1, Variadic: true}}, Match: MatchExact}, cfg.Rules["fmt.Sscan"])
+	assert.NotContains(t, cfg.Rules, "gopkg.in/yaml.v2.Unmarshal")
+	assert.NotContains(t, cfg.Rules, "encoding/safejson.Unmarshal")
+
+	cfg, err = LoadConfig("", "strict")
+	require.NoError(t, err)
+	assert.Len(t, cfg.Rules, len(defaultCfg.Rules))
+	for key, rule := range cfg.Rules {
+		require.NotNil(t, rule.AllowNil, key)
+		assert.False(t, *rule.AllowNil, key)
+	}
+	assert.Equal(t, &Rule{Args: indexArgs(0), AllowNil: new(bool)}, cfg.Rules["encoding/json.Decoder.Decode"])
+	assert.Equal(t, &Rule{Args: []Arg{{Index: 1, Variadic: true}}, AllowNil: new(bool)}, cfg.Rules["fmt.Sscan"])
+	assert.Nil(t, defaultCfg.Rules["fmt.Sscan"].AllowNil)
+
+	_, err = LoadConfig("", "unknown")
+	assert.EqualError(t, err, `unknown preset "unknown": must be one of default, minimal, stdlib, strict`)
+}
+
+func TestLoadConfigFromStdin(t *testing.T) {
+	origStdin := stdin
+	defer func() {
+		stdin = origStdin
+	}()
+	stdin = strings.NewReader(`{"github.com/palantir/example/config.Load": [0]}`)
+
+	cfg, err := LoadConfig("@-", "minimal")
+	require.NoError(t, err)
+	assert.Equal(t, argsConfig(map[string][]int{
+		"encoding/json.Unmarshal":                 {1},
+		"github.com/palantir/example/config.Load": {0},
+	}), cfg)
+}
+
+func TestMigrateConfig(t *testing.T) {
+	migrated, err := MigrateConfig(`{
+		// comments are not preserved
+		"github.com/palantir/example/config.Load": [2, 0],
+		"encoding/json.Unmarshal": null
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+    "version": 2,
+    "rules": {
+        "encoding/json.Unmarshal": null,
+        "github.com/palantir/example/config.Load": {
+            "args": [
+                0,
+                2
+            ]
+        }
+    }
+}
+`, string(migrated))
+
+	cfg, err := parseCfg(string(migrated))
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Rules: map[string]*Rule{
+			"encoding/json.Unmarshal":                 nil,
+			"github.com/palantir/example/config.Load": {Args: indexArgs(0, 2)},
+		},
+	}, cfg)
+}
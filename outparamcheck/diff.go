@@ -0,0 +1,140 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// diffHunkHeader matches the header of a hunk of a unified diff and captures the first line and the number of lines of
+// the hunk in the new version of the file.
+var diffHunkHeader = regexp.MustCompile(`^@@ -[0-9]+(?:,[0-9]+)? \+([0-9]+)(?:,([0-9]+))? @@`)
+
+// changedLines are the lines that were added or modified relative to a base revision, keyed by the absolute path of
+// the file. A nil set of lines means that the entire file is new.
+type changedLines map[string]map[int]bool
+
+// changedLinesSince returns the lines that were added or modified in the working tree of the git repository that
+// contains the provided directory relative to the merge base of HEAD and the provided revision. Files that are not
+// tracked by git (and not ignored) are considered to be entirely new.
+func changedLinesSince(dir, base string) (changedLines, error) {
+	root, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+	mergeBase, err := runGit(root, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := runGit(root, "diff", "--unified=0", "--no-color", "--no-ext-diff", "--no-renames", strings.TrimSpace(mergeBase))
+	if err != nil {
+		return nil, err
+	}
+	changed, err := parseDiff(root, diff)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit(root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range strings.Split(untracked, "\n") {
+		if file != "" {
+			changed[filepath.Join(root, filepath.FromSlash(file))] = nil
+		}
+	}
+	return changed, nil
+}
+
+// parseDiff returns the lines that were added or modified according to the provided unified diff without context
+// lines, whose paths are relative to the provided directory.
+func parseDiff(dir, diff string) (changedLines, error) {
+	changed := make(changedLines)
+	var lines map[int]bool
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			lines = nil
+			if path := strings.TrimPrefix(line, "+++ "); strings.HasPrefix(path, "b/") {
+				lines = make(map[int]bool)
+				changed[filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(path, "b/")))] = lines
+			}
+		case strings.HasPrefix(line, "@@ "):
+			match := diffHunkHeader.FindStringSubmatch(line)
+			if match == nil || lines == nil {
+				continue
+			}
+			start, err := strconv.Atoi(match[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid hunk header %s", line)
+			}
+			count := 1
+			if match[2] != "" {
+				if count, err = strconv.Atoi(match[2]); err != nil {
+					return nil, errors.Wrapf(err, "invalid hunk header %s", line)
+				}
+			}
+			for i := start; i < start+count; i++ {
+				lines[i] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read diff")
+	}
+	return changed, nil
+}
+
+// contains returns true if the line of the provided error was added or modified.
+func (c changedLines) contains(err OutParamError) bool {
+	// the paths reported by git do not contain symbolic links
+	path, evalErr := filepath.EvalSymlinks(err.Pos.Filename)
+	if evalErr != nil {
+		path = err.Pos.Filename
+	}
+	if abs, absErr := filepath.Abs(path); absErr == nil {
+		path = abs
+	}
+	lines, ok := c[path]
+	if !ok {
+		return false
+	}
+	return lines == nil || lines[err.Pos.Line]
+}
+
+// filter returns the provided errors whose lines were added or modified.
+func (c changedLines) filter(errs []OutParamError) []OutParamError {
+	var filtered []OutParamError
+	for _, err := range errs {
+		if c.contains(err) {
+			filtered = append(filtered, err)
+		}
+	}
+	return filtered
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3 +3 @@ import
+-	"fmt"
++	"encoding/json"
+@@ -10,0 +11,2 @@ func main() {
++	var x interface{}
++	json.Unmarshal(nil, x)
+@@ -20,3 +22,0 @@ func other() {
+-	a()
+-	b()
+-	c()
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package main
+-
+`
+	changed, err := parseDiff("/repo", diff)
+	require.NoError(t, err)
+	assert.Equal(t, changedLines{
+		filepath.FromSlash("/repo/main.go"): {3: true, 11: true, 12: true},
+	}, changed)
+}
+
+func TestChangedLinesSince(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir("", "")
+	require.NoError(t, err)
+	defer cleanup()
+	tmpDir, err = filepath.EvalSymlinks(tmpDir)
+	require.NoError(t, err)
+
+	git := func(args ...string) {
+		_, err := runGit(tmpDir, append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		require.NoError(t, err)
+	}
+	mainPath := filepath.Join(tmpDir, "main.go")
+	git("init", "-q")
+	require.NoError(t, ioutil.WriteFile(mainPath, []byte("package main\n\nfunc main() {\n}\n"), 0644))
+	git("add", "main.go")
+	git("commit", "-q", "-m", "initial")
+	git("branch", "base")
+	require.NoError(t, ioutil.WriteFile(mainPath, []byte("package main\n\nfunc main() {\n\tfoo()\n}\n"), 0644))
+	git("commit", "-q", "-a", "-m", "change")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "new.go"), []byte("package main\n"), 0644))
+
+	changed, err := changedLinesSince(tmpDir, "base")
+	require.NoError(t, err)
+	errAt := func(file string, line int) OutParamError {
+		return OutParamError{Pos: token.Position{Filename: filepath.Join(tmpDir, file), Line: line}}
+	}
+	errs := []OutParamError{errAt("main.go", 3), errAt("main.go", 4), errAt("new.go", 1), errAt("other.go", 1)}
+	assert.Equal(t, []OutParamError{errs[1], errs[2]}, changed.filter(errs))
+}
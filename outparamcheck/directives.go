@@ -0,0 +1,168 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// linterName is the name of the linter in the directives that suppress its errors.
+const linterName = "outparamcheck"
+
+// nolintDirective matches the "//nolint" comments of the convention used by Go linters, which suppress the errors of
+// all linters or, if followed by ":" and a comma-separated list of linter names, the errors of the listed linters on
+// the line of the comment.
+var nolintDirective = regexp.MustCompile(`^//\s?nolint(?::([\w-]+(?:,[\w-]+)*))?(?:\s|$)`)
+
+// ignoreDirective matches the "//outparamcheck:ignore" comments that suppress the errors of the statement that follows
+// them. The directive must be followed by the reason for suppressing the errors, which is captured.
+var ignoreDirective = regexp.MustCompile(`^//` + linterName + `:ignore(?:\s+(\S.*))?$`)
+
+// invalidDirectiveCheck is the check of the errors of malformed directives, which are reported whatever subchecks are
+// enabled.
+const invalidDirectiveCheck = "invalid-directive"
+
+// missingReasonProblem is the problem of an "//outparamcheck:ignore" directive that does not specify a reason.
+const missingReasonProblem = "//" + linterName + ":ignore directive is missing the reason for ignoring the errors of the statement that follows it, without which it is not honored"
+
+// ignoreFileDirective matches the "//outparamcheck:ignore-file" comments that suppress all of the errors of a file if
+// they precede its package clause. The directive may be followed by the reason for suppressing the errors.
+var ignoreFileDirective = regexp.MustCompile(`^//` + linterName + `:ignore-file(?:\s.*)?$`)
+
+// fileDirectives are the directives in the comments of a file that suppress errors.
+type fileDirectives struct {
+	// nolintLines are the lines that have a "//nolint" comment that applies to the linter.
+	nolintLines map[int]bool
+	// ignoredLines are the lines of the statements that follow an "//outparamcheck:ignore" directive.
+	ignoredLines map[int]bool
+	// ignoreFile is true if the file has an "//outparamcheck:ignore-file" directive.
+	ignoreFile bool
+	// list are the directives of the file that suppress errors in the order in which they appear.
+	list []directive
+	// missingReasons are the positions of the "//outparamcheck:ignore" directives that are not honored because they do
+	// not specify a reason.
+	missingReasons []token.Pos
+}
+
+// directive is a comment that suppresses errors.
+type directive struct {
+	Pos token.Position
+	// Kind is the kind of the directive, which is "nolint", "ignore" or "ignore-file".
+	Kind string
+	// Reason is the reason given by the directive, which is empty if it does not specify one.
+	Reason string
+}
+
+// newFileDirectives returns the directives of the provided file.
+func newFileDirectives(fset *token.FileSet, file *ast.File) *fileDirectives {
+	d := &fileDirectives{
+		nolintLines:  make(map[int]bool),
+		ignoredLines: make(map[int]bool),
+	}
+	// the lines on which the statements whose errors are ignored start
+	ignoredStmtLines := make(map[int]bool)
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			pos := fset.Position(comment.Slash)
+			if comment.Pos() < file.Package && ignoreFileDirective.MatchString(comment.Text) {
+				d.ignoreFile = true
+				reason := strings.TrimPrefix(comment.Text, "//"+linterName+":ignore-file")
+				d.list = append(d.list, directive{Pos: pos, Kind: "ignore-file", Reason: strings.TrimSpace(reason)})
+			}
+			if isNolint(comment.Text) {
+				d.nolintLines[pos.Line] = true
+				d.list = append(d.list, directive{Pos: pos, Kind: "nolint", Reason: nolintReason(comment.Text)})
+			}
+			if match := ignoreDirective.FindStringSubmatch(comment.Text); match != nil {
+				// directives that do not specify a reason are not honored
+				if match[1] == "" {
+					d.missingReasons = append(d.missingReasons, comment.Slash)
+					continue
+				}
+				ignoredStmtLines[fset.Position(group.End()).Line+1] = true
+				d.list = append(d.list, directive{Pos: pos, Kind: "ignore", Reason: strings.TrimSpace(match[1])})
+			}
+		}
+	}
+	if len(ignoredStmtLines) > 0 {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if stmt, ok := node.(ast.Stmt); ok && ignoredStmtLines[fset.Position(stmt.Pos()).Line] {
+				for line := fset.Position(stmt.Pos()).Line; line <= fset.Position(stmt.End()).Line; line++ {
+					d.ignoredLines[line] = true
+				}
+			}
+			return true
+		})
+	}
+	return d
+}
+
+// suppresses returns true if the directives suppress the errors reported at the provided position.
+func (d *fileDirectives) suppresses(pos token.Position) bool {
+	return d.ignoreFile || d.nolintLines[pos.Line] || d.ignoredLines[pos.Line]
+}
+
+// nolintReason returns the reason given by the provided "//nolint" comment, which follows a second "//" by convention,
+// such as in "//nolint:outparamcheck // m is a map".
+func nolintReason(comment string) string {
+	idx := strings.Index(comment[2:], "//")
+	if idx == -1 {
+		return ""
+	}
+	return strings.TrimSpace(comment[idx+4:])
+}
+
+// isNolint returns true if the provided comment is a "//nolint" comment that applies to the linter.
+func isNolint(comment string) bool {
+	match := nolintDirective.FindStringSubmatch(comment)
+	if match == nil {
+		return false
+	}
+	if match[1] == "" {
+		return true
+	}
+	for _, linter := range strings.Split(match[1], ",") {
+		if linter == linterName || linter == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// reportInvalidDirectives reports the directives of the provided file of the checked package that are not honored
+// because they are malformed. The directives of a file that is shared by several of the checked packages, such as a
+// package and its test variant, are only reported once.
+func (v *visitor) reportInvalidDirectives(file *ast.File) {
+	filename := v.pkg.Fset.Position(file.Pos()).Filename
+	if v.directiveFiles != nil {
+		if _, checked := v.directiveFiles.LoadOrStore(filename, true); checked {
+			return
+		}
+	}
+	for _, pos := range v.directives(filename).missingReasons {
+		v.report(pos, OutParamError{
+			Severity: SeverityError,
+			Problem:  missingReasonProblem,
+			Check:    invalidDirectiveCheck,
+		})
+	}
+}
+
+// directives returns the directives of the file with the provided name, which is a file of the checked package.
+func (v *visitor) directives(filename string) *fileDirectives {
+	if v.fileDirectives == nil {
+		v.fileDirectives = make(map[string]*fileDirectives)
+		for _, file := range v.pkg.Syntax {
+			v.fileDirectives[v.pkg.Fset.Position(file.Pos()).Filename] = newFileDirectives(v.pkg.Fset, file)
+		}
+	}
+	if d, ok := v.fileDirectives[filename]; ok {
+		return d
+	}
+	return &fileDirectives{}
+}
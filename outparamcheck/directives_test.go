@@ -0,0 +1,83 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNolint(t *testing.T) {
+	for i, tc := range []struct {
+		comment string
+		want    bool
+	}{
+		{"//nolint", true},
+		{"// nolint", true},
+		{"//nolint:outparamcheck", true},
+		{"//nolint:errcheck,outparamcheck // decoded into a map", true},
+		{"//nolint:all", true},
+		{"//nolint:errcheck", false},
+		{"//nolint:outparamcheckx", false},
+		{"//nolintfoo", false},
+		{"// this is not nolint", false},
+		{"/* nolint */", false},
+	} {
+		assert.Equal(t, tc.want, isNolint(tc.comment), "Case %d: %s", i, tc.comment)
+	}
+}
+
+func TestNolintReason(t *testing.T) {
+	for i, tc := range []struct {
+		comment string
+		want    string
+	}{
+		{"//nolint", ""},
+		{"//nolint:outparamcheck // decoded into a map", "decoded into a map"},
+		{"// nolint:all //   pointer  ", "pointer"},
+	} {
+		assert.Equal(t, tc.want, nolintReason(tc.comment), "Case %d: %s", i, tc.comment)
+	}
+}
+
+func TestReportInvalidDirectivesOnce(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "lib.go"), []byte(`package lib
+
+import "encoding/json"
+
+func Decode(data []byte, m map[string]string) {
+	//outparamcheck:ignore
+	json.Unmarshal(data, m)
+}
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "lib_test.go"), []byte(`package lib
+`), 0644))
+
+	// the file of the package is also a file of its test variant
+	pkgs, err := load([]string{"./" + tmpDir}, nil, nil, testsAll, nil)
+	require.NoError(t, err)
+
+	errs := run(pkgs, Config{})
+	require.Len(t, errs, 1)
+	errs[0].Pos.Offset = 0
+	assert.Equal(t, OutParamError{
+		Pos:      token.Position{Filename: errs[0].Pos.Filename, Line: 6, Column: 2},
+		Line:     "//outparamcheck:ignore",
+		Severity: SeverityError,
+		Problem:  missingReasonProblem,
+		Check:    invalidDirectiveCheck,
+	}, errs[0])
+	assert.Equal(t, "lib.go", filepath.Base(errs[0].Pos.Filename))
+}
@@ -0,0 +1,151 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/types"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// decodeFuncName matches the names of the functions and methods that look like decode functions.
+var decodeFuncName = regexp.MustCompile(`(?i)unmarshal|decode|scan|^parse.*into`)
+
+// DiscoverRules prints a configuration whose rules are suggestions for the functions and methods of the packages
+// matched by the provided paths that look like decode functions but are not matched by any rule of the configuration
+// of the provided options. A function looks like a decode function if its name contains "Unmarshal", "Decode" or
+// "Scan" or starts with "Parse" and contains "Into", its last result is an error and its last parameter is an empty
+// interface or a pointer. The suggestions are heuristic and should be reviewed before they are added to a
+// configuration file.
+func DiscoverRules(opts Options, paths []string) error {
+	cfg, err := LoadConfig(opts.Config, opts.Preset)
+	if err != nil {
+		return err
+	}
+	pkgs, err := load(paths, opts.skippedDirs(), opts.SkipPackages, opts.testsMode(), nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	pkgCfgs, err := packageConfigs(pkgs, cfg)
+	if err != nil {
+		return err
+	}
+	return discoverRules(pkgs, func(pkg *packages.Package) Config {
+		return pkgCfgs[pkg]
+	}).Print(os.Stdout)
+}
+
+// discoverRules returns a configuration with a rule for every function and method that is declared in the provided
+// packages outside of test files, looks like a decode function and is not matched by any rule of the configuration of
+// its package.
+func discoverRules(pkgs []*packages.Package, pkgCfg func(pkg *packages.Package) Config) Config {
+	cfg := Config{Rules: make(map[string]*Rule)}
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		var funcs []*types.Func
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			switch obj := scope.Lookup(name).(type) {
+			case *types.Func:
+				funcs = append(funcs, obj)
+			case *types.TypeName:
+				if named, ok := obj.Type().(*types.Named); ok && !obj.IsAlias() && !types.IsInterface(named) {
+					for i := 0; i < named.NumMethods(); i++ {
+						funcs = append(funcs, named.Method(i))
+					}
+				}
+			}
+		}
+		for _, fn := range funcs {
+			name, ok := declaredFuncName(fn)
+			if !ok || strings.HasSuffix(pkg.Fset.Position(fn.Pos()).Filename, "_test.go") {
+				continue
+			}
+			arg, ok := decodeFuncArg(fn)
+			if !ok || matchesAnyRule(fn, name, pkg, pkgCfg(pkg)) {
+				continue
+			}
+			cfg.Rules[name] = &Rule{Args: []Arg{arg}}
+		}
+	}
+	return cfg
+}
+
+// declaredFuncName returns the name of the rule that matches the provided function, which is the import path of its
+// package followed by the name of its receiver type (if it is a method) and its name. Unlike exportedFuncName,
+// unexported functions and methods are named since the rule may be used to check the package that declares them.
+func declaredFuncName(fn *types.Func) (string, bool) {
+	if fn.Pkg() == nil {
+		return "", false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return fn.Pkg().Path() + "." + fn.Name(), true
+	}
+	recvType := recv.Type()
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		recvType = ptr.Elem()
+	}
+	named, ok := types.Unalias(recvType).(*types.Named)
+	if !ok {
+		return "", false
+	}
+	return fn.Pkg().Path() + "." + named.Obj().Name() + "." + fn.Name(), true
+}
+
+// decodeFuncArg returns the argument that is the output parameter of the provided function if it looks like a decode
+// function. Methods through which a type decodes itself, such as Scan of sql.Scanner, decode into their receiver
+// rather than into their parameter and are not decode functions.
+func decodeFuncArg(fn *types.Func) (Arg, bool) {
+	sig := fn.Type().(*types.Signature)
+	params, results := sig.Params(), sig.Results()
+	if !decodeFuncName.MatchString(fn.Name()) || params.Len() == 0 || results.Len() == 0 {
+		return Arg{}, false
+	}
+	if !types.Identical(results.At(results.Len()-1).Type(), types.Universe.Lookup("error").Type()) {
+		return Arg{}, false
+	}
+	if sig.Recv() != nil && params.Len() == 1 && isSelfDecodeMethod(fn.Name()) {
+		return Arg{}, false
+	}
+	last := params.Len() - 1
+	typ := params.At(last).Type()
+	if sig.Variadic() {
+		typ = typ.(*types.Slice).Elem()
+	}
+	if iface, ok := typ.Underlying().(*types.Interface); ok && iface.Empty() {
+		return Arg{Index: last, Variadic: sig.Variadic()}, true
+	}
+	if _, ok := typ.Underlying().(*types.Pointer); ok && !sig.Variadic() {
+		return Arg{Index: last}, true
+	}
+	return Arg{}, false
+}
+
+// matchesAnyRule returns true if the provided function, which is declared in the provided package and matched by rules
+// with the provided name, is matched by one of the rules or signature rules of the provided configuration.
+func matchesAnyRule(fn *types.Func, name string, pkg *packages.Package, cfg Config) bool {
+	for ruleName, rule := range cfg.Rules {
+		if rule == nil || rule.Exclude {
+			continue
+		}
+		ruleName = resolveModuleRelative(ruleName, pkg.Module)
+		if matchesCall(name, pkg.PkgPath, ruleName, rule, cfg.matchMode(rule)) {
+			return true
+		}
+	}
+	for _, rule := range cfg.Signatures {
+		if _, ok := rule.outParams(fn); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestDiscoverRules(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		type Codec struct{}
+
+		func (c *Codec) Decode(data []byte, v interface{}) error { return nil }
+		func (c *Codec) Encode(v interface{}) ([]byte, error) { return nil, nil }
+
+		type Config struct{}
+
+		func (c *Config) UnmarshalYAML(value *Config) error { return nil }
+		func (c *Config) Scan(src any) error { return nil }
+
+		func unmarshalConfig(data []byte, cfg *Config) error { return nil }
+		func ScanRow(dest ...any) error { return nil }
+		func ParseFlagsInto(args []string, v any) error { return nil }
+		func ParseFlags(args []string, v any) error { return nil }
+		func DecodeString(s string) (string, error) { return s, nil }
+		func DecodeAll(data []byte, v any) {}
+		func DecodeInto(data []byte, v any) error { return nil }
+
+		func main() {}
+		`)
+	require.Len(t, pkgs, 1)
+	prefix := pkgs[0].PkgPath + "."
+
+	cfg := discoverRules(pkgs, func(pkg *packages.Package) Config {
+		return argsConfig(map[string][]int{
+			prefix + "DecodeInto": {1},
+		})
+	})
+	assert.Equal(t, map[string]*Rule{
+		prefix + "Codec.Decode":    {Args: indexArgs(1)},
+		prefix + "unmarshalConfig": {Args: indexArgs(1)},
+		prefix + "ScanRow":         {Args: []Arg{{Index: 0, Variadic: true}}},
+		prefix + "ParseFlagsInto":  {Args: indexArgs(1)},
+	}, cfg.Rules)
+
+	cfg = discoverRules(pkgs, func(pkg *packages.Package) Config {
+		return argsConfig(map[string][]int{
+			"Codec.Decode":    {1},
+			"unmarshalConfig": {1},
+			"ScanRow":         {0},
+			"ParseFlagsInto":  {1},
+			"DecodeInto":      {1},
+		})
+	})
+	assert.Empty(t, cfg.Rules)
+}
@@ -14,10 +14,20 @@ import (
 )
 
 type OutParamError struct {
-	Pos      token.Position
-	Line     string
-	Method   string
+	Pos    token.Position
+	Line   string
+	Method string
+	// Rule is the name of the rule that configures the output parameter, which is empty for the output parameters of
+	// signature rules and for the errors of subchecks that are not reported for an output parameter.
+	Rule     string
 	Argument int
+	Severity Severity
+	// Problem describes why the argument is reported. If it is empty, the argument is reported because it is not
+	// passed using '&'.
+	Problem string
+	// Check is the name of the optional subcheck that reported the error, which is empty for the errors of output
+	// parameters. The Problem of an error reported by a subcheck describes the whole error.
+	Check string
 }
 
 func (err OutParamError) Error() string {
@@ -30,8 +40,19 @@ func (err OutParamError) Error() string {
 	}
 	line = strings.TrimSpace(line)
 
+	var prefix string
+	if err.Severity == SeverityWarning {
+		prefix = "warning: "
+	}
+	if err.Check != "" {
+		return fmt.Sprintf("%s\t%s  // %s%s (%s)", pos, line, prefix, err.Problem, err.Check)
+	}
+	problem := err.Problem
+	if problem == "" {
+		problem = "requires '&'"
+	}
 	ord := humanize.Ordinal(err.Argument + 1)
-	return fmt.Sprintf("%s\t%s  // %s argument of '%s' requires '&'", pos, line, ord, err.Method)
+	return fmt.Sprintf("%s\t%s  // %s%s argument of '%s' %s", pos, line, prefix, ord, err.Method, problem)
 }
 
 type byLocation []OutParamError
@@ -0,0 +1,56 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+)
+
+// errorsAsFuncs are the keys of the functions that behave like errors.As, which panics unless its target is a non-nil
+// pointer to a type that implements error or to an interface type.
+var errorsAsFuncs = map[string]bool{
+	"errors.As":                true,
+	"github.com/pkg/errors.As": true,
+	"golang.org/x/xerrors.As":  true,
+}
+
+// errorsAsTargetProblem returns the problem of the argument with the provided index of the provided call if the call is
+// a call of errors.As and the argument is a target that errors.As does not accept, and an empty string otherwise. The
+// literal nil is reported by the rule of errors.As, which disallows it, and targets whose type is an interface are not
+// known statically and are not reported.
+func (v *visitor) errorsAsTargetProblem(call *ast.CallExpr, i int) string {
+	if !v.isErrorsAsCall(call) || i != 1 {
+		return ""
+	}
+	typ := v.pkg.TypesInfo.TypeOf(call.Args[i])
+	if typ == nil {
+		return ""
+	}
+	ptr, ok := typ.Underlying().(*types.Pointer)
+	if !ok {
+		return ""
+	}
+	elem := ptr.Elem()
+	if types.IsInterface(elem) || types.Implements(elem, errorType) {
+		return ""
+	}
+	if types.Implements(types.NewPointer(elem), errorType) {
+		return fmt.Sprintf("is a pointer to %s, which does not implement error; *%s does, so pass the address of a variable of type *%s", elem, elem, elem)
+	}
+	return fmt.Sprintf("is a pointer to %s, which does not implement error; errors.As panics unless the target points to an interface or to a type that implements error", elem)
+}
+
+// isErrorsAsCall returns true if the provided call is a call of errors.As or of a function that behaves like it. The
+// target of errors.As is assigned an error rather than decoded into by reflection, so pointers to interfaces and to
+// structs without exported fields are valid targets that the subchecks of decoded values do not report.
+func (v *visitor) isErrorsAsCall(call *ast.CallExpr) bool {
+	fn := v.calleeFunc(call)
+	return fn != nil && fn.Pkg() != nil && errorsAsFuncs[canonicalKey(fn.FullName())]
+}
+
+// errorType is the interface of the predeclared type error.
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
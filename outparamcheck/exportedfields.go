@@ -0,0 +1,109 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// customDecodeMethods are the names of the methods other than the methods whose name has the prefix "Unmarshal"
+// through which types decode themselves.
+var customDecodeMethods = map[string]bool{
+	"DecodeMsgpack": true,
+	"GobDecode":     true,
+	"Scan":          true,
+}
+
+// checkNoExportedFields reports the output parameters of the calls of the provided file that point to a struct without
+// exported fields, into which decoders that populate exported fields by reflection do not decode anything. Structs
+// without any fields, which are decoded into to validate or discard data, types that decode themselves, such as types
+// with an UnmarshalJSON method, and the targets of errors.As, which are assigned rather than decoded into, are not
+// reported.
+func (v *visitor) checkNoExportedFields(file *ast.File) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || v.isErrorsAsCall(call) {
+			return true
+		}
+		method, outArgs := v.callOutArgs(call)
+		indices := make([]int, 0, len(outArgs))
+		for i := range outArgs {
+			indices = append(indices, i)
+		}
+		sort.Ints(indices)
+		for _, i := range indices {
+			ptr, ok := types.Unalias(v.pkg.TypesInfo.TypeOf(call.Args[i])).(*types.Pointer)
+			if !ok || !hasOnlyUnexportedFields(ptr.Elem()) || decodesItself(ptr) {
+				continue
+			}
+			v.report(call.Args[i].Pos(), OutParamError{
+				Method:   method,
+				Rule:     outArgs[i].rule,
+				Argument: i,
+				Severity: SeverityWarning,
+				Problem: fmt.Sprintf("%s argument of '%s' points to %s, which has no exported fields to decode into",
+					humanize.Ordinal(i+1), method, ptr.Elem()),
+				Check: CheckNoExportedFields,
+			})
+		}
+		return true
+	})
+}
+
+// hasOnlyUnexportedFields returns true if the provided type is a struct that has fields, none of which are exported or
+// promote exported fields from an embedded struct.
+func hasOnlyUnexportedFields(typ types.Type) bool {
+	st, ok := typ.Underlying().(*types.Struct)
+	return ok && st.NumFields() > 0 && !hasExportedFields(st, make(map[*types.Struct]bool))
+}
+
+// hasExportedFields returns true if the provided struct has an exported field, including the fields that are promoted
+// from embedded structs. The provided map contains the structs that have been visited.
+func hasExportedFields(st *types.Struct, visited map[*types.Struct]bool) bool {
+	if visited[st] {
+		return false
+	}
+	visited[st] = true
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if field.Exported() {
+			return true
+		}
+		if !field.Embedded() {
+			continue
+		}
+		typ := field.Type()
+		if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		if embedded, ok := typ.Underlying().(*types.Struct); ok && hasExportedFields(embedded, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodesItself returns true if the method set of the provided pointer type has a method through which the type
+// decodes itself.
+func decodesItself(ptr *types.Pointer) bool {
+	methods := types.NewMethodSet(ptr)
+	for i := 0; i < methods.Len(); i++ {
+		if isSelfDecodeMethod(methods.At(i).Obj().Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSelfDecodeMethod returns true if a method with the provided name is a method through which a type decodes itself.
+func isSelfDecodeMethod(name string) bool {
+	return strings.HasPrefix(name, "Unmarshal") || customDecodeMethods[name]
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckNoExportedFields(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+			"errors"
+		)
+
+		type unexported struct {
+			name string
+			port int
+		}
+
+		type exported struct {
+			Name string
+		}
+
+		type embedded struct {
+			exported
+			id int
+		}
+
+		type embeddedPtr struct {
+			*exported
+		}
+
+		type custom struct {
+			name string
+		}
+
+		func (c *custom) UnmarshalJSON(data []byte) error {
+			return json.Unmarshal(data, &c.name)
+		}
+
+		type recursive struct {
+			*recursive
+		}
+
+		type myErr struct {
+			msg string
+		}
+
+		func (e myErr) Error() string {
+			return e.msg
+		}
+
+		func main() {
+			var u unexported
+			_ = json.Unmarshal(nil, &u)
+			p := &unexported{}
+			_ = json.Unmarshal(nil, p)
+
+			var e exported
+			_ = json.Unmarshal(nil, &e)
+			var em embedded
+			_ = json.Unmarshal(nil, &em)
+			var ep embeddedPtr
+			_ = json.Unmarshal(nil, &ep)
+			var c custom
+			_ = json.Unmarshal(nil, &c)
+			var empty struct{}
+			_ = json.Unmarshal(nil, &empty)
+			var r recursive
+			_ = json.Unmarshal(nil, &r)
+			var me myErr
+			_ = errors.As(nil, &me)
+		}
+		`)
+	cfg := argsConfig(map[string][]int{
+		"encoding/json.Unmarshal": {1},
+		"errors.As":               {1},
+	})
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 612, Line: 49, Column: 28}, Line: "_ = json.Unmarshal(nil, &u)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Severity: SeverityWarning, Problem: "2nd argument of 'Unmarshal' points to " + pkgs[0].PkgPath + ".unexported, which has no exported fields to decode into", Check: CheckNoExportedFields},
+		{Pos: token.Position{Offset: 665, Line: 51, Column: 28}, Line: "_ = json.Unmarshal(nil, p)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Severity: SeverityWarning, Problem: "2nd argument of 'Unmarshal' points to " + pkgs[0].PkgPath + ".unexported, which has no exported fields to decode into", Check: CheckNoExportedFields},
+		{Pos: token.Position{Offset: 973, Line: 64, Column: 28}, Line: "_ = json.Unmarshal(nil, &r)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Severity: SeverityWarning, Problem: "2nd argument of 'Unmarshal' points to " + pkgs[0].PkgPath + ".recursive, which has no exported fields to decode into", Check: CheckNoExportedFields},
+	}, runChecks(t, pkgs, cfg, CheckNoExportedFields))
+}
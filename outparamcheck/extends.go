@@ -0,0 +1,132 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// moduleExtendsPrefix is the prefix of the "extends" entries that refer to a configuration in a Go module. The prefix is
+// followed by the module path, optionally followed by the path of a configuration file or of a directory that contains
+// a configuration file within the module.
+const moduleExtendsPrefix = "module:"
+
+// loadCfgFile loads the configuration in the provided file and resolves the configurations that it extends. extendedBy
+// contains the absolute paths of the configuration files that (transitively) extend the file and is used to detect
+// cycles.
+func loadCfgFile(cfgPath string, extendedBy []string) (Config, error) {
+	cfgBytes, err := ioutil.ReadFile(cfgPath)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "failed to read file %s", cfgPath)
+	}
+	cfg, err := loadCfg(string(cfgBytes))
+	if err != nil {
+		return Config{}, err
+	}
+	absPath, err := filepath.Abs(cfgPath)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "failed to determine absolute path of %s", cfgPath)
+	}
+	return resolveExtends(cfg, filepath.Dir(absPath), append(extendedBy, absPath))
+}
+
+// resolveExtends returns the provided configuration applied on top of the configurations that it extends (which are
+// applied in order). Relative paths in "extends" are resolved against the provided directory, which is also the
+// directory in which the versions of the modules referred to by "extends" are determined.
+func resolveExtends(cfg Config, dir string, extendedBy []string) (Config, error) {
+	if len(cfg.Extends) == 0 {
+		return cfg, nil
+	}
+
+	var resolved Config
+	for _, extends := range cfg.Extends {
+		extendsPath, err := resolveExtendsPath(extends, dir)
+		if err != nil {
+			return Config{}, errors.Wrapf(err, "failed to resolve %q", extends)
+		}
+		for _, curr := range extendedBy {
+			if curr == extendsPath {
+				return Config{}, errors.Errorf("configuration %s extends itself: %s", extendsPath,
+					strings.Join(append(extendedBy, extendsPath), " -> "))
+			}
+		}
+		extendedCfg, err := loadCfgFile(extendsPath, extendedBy)
+		if err != nil {
+			return Config{}, errors.Wrapf(err, "failed to load configuration extended using %q", extends)
+		}
+		resolved = resolved.Merge(extendedCfg)
+	}
+	cfg.Extends = nil
+	return resolved.Merge(cfg), nil
+}
+
+// resolveExtendsPath returns the absolute path of the configuration file referred to by the provided "extends" entry.
+// If the entry refers to a directory, the path of the configuration file in the directory is returned.
+func resolveExtendsPath(extends, dir string) (string, error) {
+	var extendsPath string
+	if strings.HasPrefix(extends, moduleExtendsPrefix) {
+		modulePath, err := moduleFilePath(strings.TrimPrefix(extends, moduleExtendsPrefix), dir)
+		if err != nil {
+			return "", err
+		}
+		extendsPath = modulePath
+	} else {
+		extendsPath = filepath.FromSlash(extends)
+		if !filepath.IsAbs(extendsPath) {
+			extendsPath = filepath.Join(dir, extendsPath)
+		}
+	}
+	if fi, err := os.Stat(extendsPath); err == nil && fi.IsDir() {
+		extendsPath = filepath.Join(extendsPath, ConfigFileName)
+	}
+	return filepath.Clean(extendsPath), nil
+}
+
+// moduleFilePath returns the path on disk of the provided path within a Go module, which consists of a module path
+// followed by an optional path within the module. The version of the module is the version selected by the build list
+// of the main module of the provided directory, and the module is downloaded if it is not yet in the module cache.
+func moduleFilePath(path, dir string) (string, error) {
+	listOutput, err := runGo(dir, "list", "-m", "-json", "all")
+	if err != nil {
+		return "", err
+	}
+
+	type module struct {
+		Path    string
+		Version string
+		Dir     string
+	}
+	var match *module
+	dec := json.NewDecoder(strings.NewReader(listOutput))
+	for dec.More() {
+		var mod module
+		if err := dec.Decode(&mod); err != nil {
+			return "", errors.Wrapf(err, "failed to parse output of go list")
+		}
+		if (path == mod.Path || strings.HasPrefix(path, mod.Path+"/")) && (match == nil || len(mod.Path) > len(match.Path)) {
+			match = &mod
+		}
+	}
+	if match == nil {
+		return "", errors.Errorf("%s is not provided by any module in the build list of the module in %s", path, dir)
+	}
+
+	if match.Dir == "" {
+		downloadOutput, err := runGo(dir, "mod", "download", "-json", match.Path+"@"+match.Version)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal([]byte(downloadOutput), match); err != nil {
+			return "", errors.Wrapf(err, "failed to parse output of go mod download")
+		}
+	}
+	return filepath.Join(match.Dir, filepath.FromSlash(strings.TrimPrefix(path, match.Path))), nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigExtends(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir("", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	for file, content := range map[string]string{
+		"base.json":                  `{"version": 2, "rules": {"example.com/a.Load": [0], "example.com/b.Load": [1]}, "excludePackages": ["gen"]}`,
+		"shared/.outparamcheck.json": `{"version": 2, "extends": ["../base.json"], "rules": {"example.com/c.Load": [2]}}`,
+		"project/config.json":        `{"version": 2, "extends": ["../shared"], "rules": {"example.com/b.Load": null}}`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, file)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0644))
+	}
+
+	cfg, err := loadCfgFromPath(filepath.Join(tmpDir, "project", "config.json"))
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Rules: map[string]*Rule{
+			"example.com/a.Load": {Args: indexArgs(0)},
+			"example.com/c.Load": {Args: indexArgs(2)},
+		},
+		ExcludePackages: []string{"gen"},
+	}, cfg)
+}
+
+func TestLoadConfigExtendsCycle(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir("", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "a.json"), []byte(`{"version": 2, "extends": ["b.json"]}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "b.json"), []byte(`{"version": 2, "extends": ["a.json"]}`), 0644))
+
+	_, err = loadCfgFromPath(filepath.Join(tmpDir, "a.json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "extends itself")
+}
+
+func TestLoadConfigExtendsModule(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir("", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	for file, content := range map[string]string{
+		"lint-config/go.mod":                            "module example.com/lint-config\n\ngo 1.23\n",
+		"lint-config/outparamcheck/.outparamcheck.json": `{"version": 2, "rules": {"example.com/a.Load": [0]}}`,
+		"project/go.mod":                                "module example.com/project\n\ngo 1.23\n\nrequire example.com/lint-config v1.0.0\n\nreplace example.com/lint-config => ../lint-config\n",
+		"project/.outparamcheck.json":                   `{"version": 2, "extends": ["module:example.com/lint-config/outparamcheck"], "rules": {"example.com/b.Load": [1]}}`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, file)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0644))
+	}
+
+	cfg, err := loadCfgFromPath(filepath.Join(tmpDir, "project", ConfigFileName))
+	require.NoError(t, err)
+	assert.Equal(t, Config{
+		Rules: map[string]*Rule{
+			"example.com/a.Load": {Args: indexArgs(0)},
+			"example.com/b.Load": {Args: indexArgs(1)},
+		},
+	}, cfg)
+
+	_, err = resolveExtends(Config{Extends: []string{"module:example.com/missing"}}, filepath.Join(tmpDir, "project"), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not provided by any module")
+}
@@ -0,0 +1,87 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// isGoFileArg returns true if the provided argument is the path of a Go file rather than a package pattern. Like the go
+// command, every argument with the suffix ".go" is the path of a Go file.
+func isGoFileArg(arg string) bool {
+	return strings.HasSuffix(arg, ".go")
+}
+
+// goFileQueries returns the provided arguments with the path of every Go file replaced by a query for the package that
+// contains the file, so the file is type-checked along with the other files of its package.
+func goFileQueries(args []string) ([]string, error) {
+	queries := make([]string, len(args))
+	for i, arg := range args {
+		if !isGoFileArg(arg) {
+			queries[i] = arg
+			continue
+		}
+		absPath, err := goFileArgPath(arg)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = "file=" + absPath
+	}
+	return queries, nil
+}
+
+// goFileArgPath returns the absolute path of the Go file with the provided path, which must exist.
+func goFileArgPath(arg string) (string, error) {
+	absPath, err := filepath.Abs(arg)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to determine absolute path of %s", arg)
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		return "", errors.Wrapf(err, "failed to check %s", arg)
+	}
+	return absPath, nil
+}
+
+// goFiles is a set of the absolute paths of Go files.
+type goFiles map[string]bool
+
+// newGoFileArgs returns the Go files among the provided arguments, or nil if none of the arguments is a Go file.
+func newGoFileArgs(args []string) (goFiles, error) {
+	var files goFiles
+	for _, arg := range args {
+		if !isGoFileArg(arg) {
+			continue
+		}
+		absPath, err := goFileArgPath(arg)
+		if err != nil {
+			return nil, err
+		}
+		if files == nil {
+			files = make(goFiles)
+		}
+		files[absPath] = true
+	}
+	return files, nil
+}
+
+// filter returns the provided errors that are reported in the files of the set. A nil set returns all of the provided
+// errors, so only the errors of the Go files that are provided as arguments are reported when any argument is a Go
+// file.
+func (f goFiles) filter(errs []OutParamError) []OutParamError {
+	if f == nil {
+		return errs
+	}
+	var filtered []OutParamError
+	for _, err := range errs {
+		if f[filepath.Clean(err.Pos.Filename)] {
+			filtered = append(filtered, err)
+		}
+	}
+	return filtered
+}
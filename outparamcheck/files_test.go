@@ -0,0 +1,73 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoFileArgs(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "a.go"), []byte(`package lib
+
+import (
+	"encoding/json"
+)
+
+func A() {
+	var x interface{}
+	_ = json.Unmarshal(nil, x)
+	_ = json.Unmarshal(nil, newConfig())
+}
+`), 0644))
+	// declares the function that a.go calls, so a.go type-checks only if it is loaded along with its package
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "b.go"), []byte(`package lib
+
+import (
+	"encoding/json"
+)
+
+type config struct{}
+
+func newConfig() *config { return nil }
+
+func B() {
+	var x interface{}
+	_ = json.Unmarshal(nil, x)
+}
+`), 0644))
+
+	args := []string{"./" + path.Join(tmpDir, "a.go")}
+	pkgs, err := load(args, nil, nil, testsAll, nil)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	assert.Len(t, pkgs[0].Syntax, 2)
+
+	fileArgs, err := newGoFileArgs(args)
+	require.NoError(t, err)
+	errs := fileArgs.filter(run(pkgs, argsConfig(map[string][]int{
+		"encoding/json.Unmarshal": {1},
+	})))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "a.go", filepath.Base(errs[0].Pos.Filename))
+	assert.Equal(t, 9, errs[0].Pos.Line)
+
+	_, err = newGoFileArgs([]string{path.Join(tmpDir, "missing.go")})
+	assert.Error(t, err)
+
+	fileArgs, err = newGoFileArgs([]string{"./..."})
+	require.NoError(t, err)
+	assert.Nil(t, fileArgs)
+}
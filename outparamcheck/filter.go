@@ -0,0 +1,158 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// testdataDir is the name of the directories that contain test fixtures, which are ignored by the go command.
+const testdataDir = "testdata"
+
+// excludePackagesInDir returns the provided packages without the packages whose directory is within a directory with the
+// provided name, such as "vendor". Only the directories within the working directory are considered, so the packages of
+// a project that is itself located in such a directory are not excluded.
+func excludePackagesInDir(pkgs []*packages.Package, name string) []*packages.Package {
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = ""
+	}
+	var filtered []*packages.Package
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) > 0 && inDir(wd, filepath.Dir(pkg.GoFiles[0]), name) {
+			continue
+		}
+		filtered = append(filtered, pkg)
+	}
+	return filtered
+}
+
+// inDir returns true if the provided directory, relative to the provided base directory, is or is within a directory
+// with the provided name.
+func inDir(base, dir, name string) bool {
+	if base != "" {
+		if rel, err := filepath.Rel(base, dir); err == nil {
+			dir = rel
+		}
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(dir), "/") {
+		if elem == name {
+			return true
+		}
+	}
+	return false
+}
+
+// excludePackagePaths returns the import paths of the packages that match the provided package patterns other than
+// the packages whose import paths match any of the provided exclusion patterns. Only the names of the packages are
+// loaded, so the excluded packages are never type-checked. The provided patterns are returned as-is if they do not
+// match any excluded package, which preserves patterns that do not refer to packages by import path (such as the paths
+// of Go files).
+func excludePackagePaths(patterns, exclusions []string) ([]string, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName}, patterns...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list packages")
+	}
+	excluded := false
+	var paths []string
+	for _, pkg := range pkgs {
+		if matchesAnyPackagePattern(exclusions, pkg.PkgPath) {
+			excluded = true
+			continue
+		}
+		paths = append(paths, pkg.PkgPath)
+	}
+	if !excluded {
+		return patterns, nil
+	}
+	return paths, nil
+}
+
+// excludeExternalTests returns the provided packages without the external test packages, whose package name has the
+// suffix "_test".
+func excludeExternalTests(pkgs []*packages.Package) []*packages.Package {
+	var filtered []*packages.Package
+	for _, pkg := range pkgs {
+		if strings.HasSuffix(pkg.Name, "_test") && strings.HasSuffix(pkg.PkgPath, "_test") {
+			continue
+		}
+		filtered = append(filtered, pkg)
+	}
+	return filtered
+}
+
+// regexpOnlyChars are the characters that identify a file pattern as a regular expression rather than a glob pattern.
+const regexpOnlyChars = `^$\()|+{`
+
+// fileFilter matches the files that are not checked.
+type fileFilter struct {
+	// wd is the working directory, relative to which the paths of files are matched.
+	wd      string
+	regexps []*regexp.Regexp
+	globs   []string
+}
+
+// newFileFilter returns a filter that matches the files that match any of the provided patterns. A pattern that contains
+// any of the characters in regexpOnlyChars or the sequence ".*" is a regular expression that is matched against the
+// slash-separated path of a file relative to the working directory, such as `.*\.pb\.go$`. Any other pattern is a glob
+// pattern that is matched against the name of a file, such as "bindata.go" or "*_generated.go", or against the
+// slash-separated path of a file relative to the working directory if the pattern contains a slash.
+func newFileFilter(patterns []string) (*fileFilter, error) {
+	f := &fileFilter{}
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, regexpOnlyChars) || strings.Contains(pattern, ".*") {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid file pattern %s", pattern)
+			}
+			f.regexps = append(f.regexps, re)
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid file pattern %s", pattern)
+		}
+		f.globs = append(f.globs, pattern)
+	}
+	if len(f.regexps) > 0 || len(f.globs) > 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to determine working directory")
+		}
+		f.wd = wd
+	}
+	return f, nil
+}
+
+// matches returns true if the file with the provided path matches the filter. A nil filter does not match any file.
+func (f *fileFilter) matches(path string) bool {
+	if f == nil || len(f.regexps) == 0 && len(f.globs) == 0 {
+		return false
+	}
+	if rel, err := filepath.Rel(f.wd, path); err == nil && filepath.IsAbs(path) {
+		path = rel
+	}
+	path = filepath.ToSlash(path)
+	for _, re := range f.regexps {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	for _, glob := range f.globs {
+		name := path
+		if !strings.Contains(glob, "/") {
+			name = name[strings.LastIndex(name, "/")+1:]
+		}
+		if matched, _ := filepath.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestInDir(t *testing.T) {
+	for i, tc := range []struct {
+		base string
+		dir  string
+		want bool
+	}{
+		{"/home/user/project", "/home/user/project/vendor/github.com/foo/bar", true},
+		{"/home/user/project", "/home/user/project/internal/vendor", true},
+		{"/home/user/project", "/home/user/project/internal/vendors", false},
+		{"/home/vendor/project", "/home/vendor/project/internal", false},
+		{"", "vendor/golang.org/x/net", true},
+	} {
+		assert.Equal(t, tc.want, inDir(tc.base, tc.dir, vendorDir), "Case %d", i)
+	}
+}
+
+func TestExcludePackagesInDir(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	pkgs := []*packages.Package{
+		{PkgPath: "example.com/project", GoFiles: []string{filepath.Join(wd, "main.go")}},
+		{PkgPath: "github.com/foo/bar", GoFiles: []string{filepath.Join(wd, "vendor", "github.com", "foo", "bar", "bar.go")}},
+		{PkgPath: "example.com/project/empty"},
+	}
+	assert.Equal(t, []*packages.Package{pkgs[0], pkgs[2]}, excludePackagesInDir(pkgs, vendorDir))
+}
+
+func TestLoadSkipsTestdata(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	brokenDir := filepath.Join(tmpDir, testdataDir, "broken")
+	require.NoError(t, os.MkdirAll(brokenDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(brokenDir, "broken.go"), []byte("package broken\n\nfunc broken() {\n"), 0644))
+
+	patterns := []string{"./" + tmpDir, "./" + filepath.ToSlash(brokenDir)}
+	_, err = load(patterns, nil, nil, testsAll, nil)
+	assert.Error(t, err)
+
+	pkgs, err := load(patterns, []string{testdataDir}, nil, testsAll, nil)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	assert.Equal(t, "main", pkgs[0].Name)
+}
+
+func TestFileFilter(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	filter, err := newFileFilter([]string{`.*\.pb\.go$`, "bindata.go", "*_generated.go", "internal/legacy/*.go"})
+	require.NoError(t, err)
+	for i, tc := range []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(wd, "api", "service.pb.go"), true},
+		{filepath.Join(wd, "api", "service.go"), false},
+		{filepath.Join(wd, "assets", "bindata.go"), true},
+		{filepath.Join(wd, "assets", "mybindata.go"), false},
+		{filepath.Join(wd, "model_generated.go"), true},
+		{filepath.Join(wd, "internal", "legacy", "config.go"), true},
+		{filepath.Join(wd, "legacy", "config.go"), false},
+	} {
+		assert.Equal(t, tc.want, filter.matches(tc.path), "Case %d: %s", i, tc.path)
+	}
+
+	var nilFilter *fileFilter
+	assert.False(t, nilFilter.matches(filepath.Join(wd, "main.go")))
+
+	_, err = newFileFilter([]string{`(\.go$`})
+	assert.Error(t, err)
+}
+
+func TestLoadSkipsPackages(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	legacyDir := filepath.Join(tmpDir, "legacy")
+	require.NoError(t, os.MkdirAll(legacyDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	// the legacy package does not compile, so loading it would fail
+	require.NoError(t, ioutil.WriteFile(filepath.Join(legacyDir, "legacy.go"), []byte("package legacy\n\nvar x int = \"\"\n"), 0644))
+
+	patterns := []string{"./" + tmpDir + "/..."}
+	_, err = load(patterns, nil, nil, testsAll, nil)
+	assert.Error(t, err)
+
+	pkgs, err := load(patterns, nil, []string{"github.com/palantir/outparamcheck/.../legacy"}, testsAll, nil)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	assert.Equal(t, "main", pkgs[0].Name)
+
+	pkgs, err = load(patterns, nil, []string{"github.com/palantir/outparamcheck/..."}, testsAll, nil)
+	require.NoError(t, err)
+	assert.Empty(t, pkgs)
+}
+
+func TestLoadTests(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "lib.go"), []byte("package lib\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "lib_test.go"), []byte("package lib\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "lib_x_test.go"), []byte("package lib_test\n"), 0644))
+
+	for i, tc := range []struct {
+		tests testsMode
+		want  []string
+	}{
+		{testsAll, []string{"lib", "lib", "lib_test"}},
+		{testsInternal, []string{"lib", "lib"}},
+		{testsNone, []string{"lib"}},
+	} {
+		pkgs, err := load([]string{"./" + tmpDir}, nil, nil, tc.tests, nil)
+		require.NoError(t, err)
+		var names []string
+		for _, pkg := range pkgs {
+			// the generated test main package is not of interest
+			if !strings.HasSuffix(pkg.PkgPath, ".test") {
+				names = append(names, pkg.Name)
+			}
+		}
+		sort.Strings(names)
+		assert.Equal(t, tc.want, names, "Case %d", i)
+	}
+}
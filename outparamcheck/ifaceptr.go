@@ -0,0 +1,125 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"github.com/dustin/go-humanize"
+)
+
+// checkPointersToInterfaces reports the output parameters of the calls of the provided file that are passed the address
+// of a variable of an interface type, which usually indicates a misunderstanding of how decoding populates interfaces:
+// a non-empty interface that holds a concrete value cannot be decoded into, since only a pointer that it holds can, and
+// an empty interface that holds a pointer is replaced by a generic value (such as a map) by some decoders instead of
+// being decoded into. The targets of errors.As, for which a pointer to an interface is the idiomatic way to match
+// errors by behavior, are not reported.
+func (v *visitor) checkPointersToInterfaces(file *ast.File) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || v.isErrorsAsCall(call) {
+			return true
+		}
+		method, outArgs := v.callOutArgs(call)
+		indices := make([]int, 0, len(outArgs))
+		for i := range outArgs {
+			indices = append(indices, i)
+		}
+		sort.Ints(indices)
+		for _, i := range indices {
+			if problem := v.interfacePointerProblem(call.Args[i]); problem != "" {
+				v.report(call.Args[i].Pos(), OutParamError{
+					Method:   method,
+					Rule:     outArgs[i].rule,
+					Argument: i,
+					Severity: SeverityError,
+					Problem:  fmt.Sprintf("%s argument of '%s' %s", humanize.Ordinal(i+1), method, problem),
+					Check:    CheckPointerToInterface,
+				})
+			}
+		}
+		return true
+	})
+}
+
+// interfacePointerProblem returns the problem of the provided argument if it is the address of a variable of a
+// non-empty interface type that holds a concrete value that is not a pointer or of a variable of an empty interface type
+// that holds an address, and an empty string otherwise.
+func (v *visitor) interfacePointerProblem(arg ast.Expr) string {
+	unary, ok := ast.Unparen(arg).(*ast.UnaryExpr)
+	if !ok || unary.Op != token.AND {
+		return ""
+	}
+	typ := v.pkg.TypesInfo.TypeOf(unary.X)
+	if typ == nil {
+		return ""
+	}
+	iface, ok := typ.Underlying().(*types.Interface)
+	if !ok {
+		return ""
+	}
+	ident, ok := ast.Unparen(unary.X).(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	if !iface.Empty() {
+		if v.holdsConcreteValue(ident) {
+			return fmt.Sprintf("is a pointer to %s, which holds a concrete value of the interface %s that cannot be decoded into; pass a pointer to a concrete type instead", ident.Name, typ)
+		}
+		return ""
+	}
+	if v.holdsAddr(ident) {
+		return fmt.Sprintf("is a pointer to %s, which already holds a pointer; pass %s instead", ident.Name, ident.Name)
+	}
+	return ""
+}
+
+// holdsAddr returns true if the variable that the provided identifier refers to holds an address at the position of
+// the identifier. Unlike isAddr, the variable may have its address taken, since the address is taken by the argument
+// that refers to it.
+func (v *visitor) holdsAddr(ident *ast.Ident) bool {
+	obj, ok := v.pkg.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return false
+	}
+	values, ok := v.reachingAssignments(obj, ident.Pos())
+	if !ok {
+		return false
+	}
+	for _, value := range values {
+		if !v.isAssignedAddr(value.rhs, value.index) {
+			return false
+		}
+	}
+	return true
+}
+
+// holdsConcreteValue returns true if the variable of an interface type that the provided identifier refers to holds a
+// value of a concrete type that is not a pointer at the position of the identifier, which is not the case if it may be
+// nil or hold a value whose type is not known statically.
+func (v *visitor) holdsConcreteValue(ident *ast.Ident) bool {
+	obj, ok := v.pkg.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return false
+	}
+	values, ok := v.reachingAssignments(obj, ident.Pos())
+	if !ok {
+		return false
+	}
+	for _, value := range values {
+		typ := v.assignedType(value.rhs, value.index)
+		if typ == nil || types.IsInterface(typ) || isPointer(typ) {
+			return false
+		}
+		if basic, ok := typ.(*types.Basic); ok && basic.Kind() == types.UntypedNil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPointersToInterfaces(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+			"errors"
+			"fmt"
+		)
+
+		type Config struct{}
+
+		type Name string
+
+		func (n Name) String() string { return string(n) }
+
+		func main() {
+			var s fmt.Stringer
+			_ = json.Unmarshal(nil, &s)
+
+			var named fmt.Stringer = Name("x")
+			_ = json.Unmarshal(nil, &named)
+
+			n := Name("x")
+			var ptr fmt.Stringer = &n
+			_ = json.Unmarshal(nil, &ptr)
+
+			var cfg Config
+			var v interface{} = &cfg
+			_ = json.Unmarshal(nil, &v)
+			_ = json.Unmarshal(nil, v)
+
+			var generic interface{}
+			_ = json.Unmarshal(nil, &generic)
+
+			var p *Config
+			_ = json.Unmarshal(nil, &p)
+
+			var te interface{ Timeout() bool }
+			_ = errors.As(nil, &te)
+		}
+		`)
+	cfg := argsConfig(map[string][]int{
+		"encoding/json.Unmarshal": {1},
+		"errors.As":               {1},
+	})
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 306, Line: 21, Column: 28}, Line: "_ = json.Unmarshal(nil, &named)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' is a pointer to named, which holds a concrete value of the interface fmt.Stringer that cannot be decoded into; pass a pointer to a concrete type instead", Check: CheckPointerToInterface},
+		{Pos: token.Position{Offset: 469, Line: 29, Column: 28}, Line: "_ = json.Unmarshal(nil, &v)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' is a pointer to v, which already holds a pointer; pass v instead", Check: CheckPointerToInterface},
+		{Pos: token.Position{Offset: 500, Line: 30, Column: 28}, Line: "_ = json.Unmarshal(nil, v)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+	}, runChecks(t, pkgs, cfg, CheckPointerToInterface))
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// matchesImplementer returns true if the provided call is a call of a method that implements the method of an
+// interface that is named by the provided rule, which makes rules on interface methods apply to every type that
+// implements the interface. Only rules that are matched using MatchSuffix or MatchExact apply to implementers.
+func (v *visitor) matchesImplementer(call *ast.CallExpr, pkgPath, name string, rule *Rule, mode MatchMode) bool {
+	if mode != MatchSuffix && mode != MatchExact || isGlobName(name) || rule.UnmarshalLike {
+		return false
+	}
+	if len(rule.Packages) > 0 && !matchesAnyPackagePattern(rule.Packages, canonicalKey(pkgPath)) {
+		return false
+	}
+	sel, ok := v.callTarget(call).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	selection, ok := v.pkg.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal {
+		return false
+	}
+	if !strings.HasSuffix(name, "."+sel.Sel.Name) {
+		return false
+	}
+	recv := selection.Recv()
+	for _, iface := range v.ruleInterfaces(name, mode) {
+		if types.Implements(recv, iface) {
+			return true
+		}
+		if _, isPtr := recv.Underlying().(*types.Pointer); !isPtr && types.Implements(types.NewPointer(recv), iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleInterfaces returns the interfaces defined in the checked package or its dependencies that declare the method
+// named by the provided rule name, which has the form "pkg.Interface.Method".
+func (v *visitor) ruleInterfaces(name string, mode MatchMode) []*types.Interface {
+	cacheKey := fmt.Sprintf("%s:%v", name, mode)
+	if cached, ok := v.ifaces[cacheKey]; ok {
+		return cached
+	}
+
+	var ifaces []*types.Interface
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		method := name[idx+1:]
+		typeName := name[:idx]
+		// type names do not contain '.', so the name of the interface follows the last '.' of the type name
+		ifaceName := typeName[strings.LastIndex(typeName, ".")+1:]
+		for _, pkg := range v.dependencies() {
+			obj, ok := pkg.Scope().Lookup(ifaceName).(*types.TypeName)
+			if !ok || !types.IsInterface(obj.Type()) {
+				continue
+			}
+			if !matchesKey(fmt.Sprintf("%v.%v", pkg.Path(), ifaceName), typeName, mode) {
+				continue
+			}
+			iface := obj.Type().Underlying().(*types.Interface)
+			for i := 0; i < iface.NumMethods(); i++ {
+				if iface.Method(i).Name() == method {
+					ifaces = append(ifaces, iface)
+					break
+				}
+			}
+		}
+	}
+	if v.ifaces == nil {
+		v.ifaces = make(map[string][]*types.Interface)
+	}
+	v.ifaces[cacheKey] = ifaces
+	return ifaces
+}
+
+// dependencies returns the checked package and all of the packages that it depends on.
+func (v *visitor) dependencies() []*types.Package {
+	if v.deps == nil {
+		packages.Visit([]*packages.Package{v.pkg}, nil, func(pkg *packages.Package) {
+			if pkg.Types != nil {
+				v.deps = append(v.deps, pkg.Types)
+			}
+		})
+	}
+	return v.deps
+}
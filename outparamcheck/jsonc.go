@@ -0,0 +1,63 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"strings"
+)
+
+// stripJSONC converts JSON with comments (JSONC) into standard JSON. Line comments ("// ...") and block comments
+// ("/* ... */") outside of string literals are removed, as are trailing commas that precede the end of an object or
+// an array. Removed content is replaced with spaces (newlines are preserved) so that the offsets and line numbers of
+// the remaining content are unchanged.
+func stripJSONC(input string) string {
+	out := []byte(input)
+	blank := func(start, end int) {
+		for i := start; i < end; i++ {
+			if out[i] != '\n' && out[i] != '\r' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	// index of the most recent comma that has not yet been followed by a value
+	pendingComma := -1
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case c == '"':
+			pendingComma = -1
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			end := len(out)
+			if idx := strings.IndexByte(input[i:], '\n'); idx != -1 {
+				end = i + idx
+			}
+			blank(i, end)
+			i = end - 1
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := len(out)
+			if idx := strings.Index(input[i+2:], "*/"); idx != -1 {
+				end = i + 2 + idx + len("*/")
+			}
+			blank(i, end)
+			i = end - 1
+		case c == ',':
+			pendingComma = i
+		case c == '}' || c == ']':
+			if pendingComma != -1 {
+				blank(pendingComma, pendingComma+1)
+			}
+			pendingComma = -1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			pendingComma = -1
+		}
+	}
+	return string(out)
+}
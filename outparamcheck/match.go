@@ -0,0 +1,171 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// vendorDir is the name of the directory that contains vendored packages.
+const vendorDir = "vendor"
+
+// keyRegexps caches the compiled regular expressions of the names of rules that use MatchRegex.
+var keyRegexps sync.Map // map[string]*regexp.Regexp
+
+// matchesCall returns true if the provided rule with the provided name matches the called function with the provided
+// key, which is defined in the package with the provided import path, using the provided matching mode.
+func matchesCall(key, pkgPath, name string, rule *Rule, mode MatchMode) bool {
+	if len(rule.Packages) > 0 && !matchesAnyPackagePattern(rule.Packages, canonicalKey(pkgPath)) {
+		return false
+	}
+	if rule.UnmarshalLike {
+		// the name of the rule is the import path of the package, every exported function and method of which matches
+		return pkgPath != "" && token.IsExported(key[strings.LastIndex(key, ".")+1:]) && matchesKey(pkgPath, name, mode)
+	}
+	return matchesKey(key, name, mode)
+}
+
+// moduleRelativePrefix is the prefix of the rule names that are relative to the module of the checked package.
+const moduleRelativePrefix = "./"
+
+// resolveModuleRelative returns the provided rule name resolved against the path of the provided module if it is
+// relative to the module (such as "./internal/config.Load"), and the name itself otherwise. Module-relative names
+// make it possible to configure the functions of the checked module without hardcoding its path.
+func resolveModuleRelative(name string, module *packages.Module) string {
+	if !strings.HasPrefix(name, moduleRelativePrefix) || module == nil {
+		return name
+	}
+	return module.Path + "/" + strings.TrimPrefix(name, moduleRelativePrefix)
+}
+
+// matchesKey returns true if the provided key of a called function matches the name of a rule using the provided
+// matching mode.
+func matchesKey(key, name string, mode MatchMode) bool {
+	if mode != MatchRegex {
+		name = stripTypeParams(name)
+	}
+	if mode != MatchRegex && isGlobName(name) {
+		re := keyRegexp(globRegexp(canonicalKey(name)))
+		return re != nil && re.MatchString(canonicalKey(key))
+	}
+	switch mode {
+	case MatchExact:
+		return canonicalKey(key) == canonicalKey(name)
+	case MatchRegex:
+		re := keyRegexp(name)
+		return re != nil && re.MatchString(canonicalKey(key))
+	default:
+		// the name must consist of entire elements of the key, so "json.Unmarshal" matches "encoding/json.Unmarshal"
+		// but not "example.com/myjson.Unmarshal"
+		key, name = canonicalKey(key), canonicalKey(name)
+		if name == "" || !strings.HasSuffix(key, name) {
+			return false
+		}
+		return len(key) == len(name) || isElementSeparator(name[0]) || isElementSeparator(key[len(key)-len(name)-1])
+	}
+}
+
+// isElementSeparator returns true if the provided character separates the elements of a function key.
+func isElementSeparator(c byte) bool {
+	return c == '/' || c == '.'
+}
+
+// majorVersionSuffix matches the major version suffix of a module path, such as the "/v2" of
+// "github.com/palantir/example/v2".
+var majorVersionSuffix = regexp.MustCompile(`/v([2-9]|[1-9][0-9]+)$`)
+
+// unversionedPkgPath returns the provided import path of a package of the provided module with the major version suffix
+// of the module path removed, so that "github.com/palantir/example/v2/codec" becomes
+// "github.com/palantir/example/codec". The import path is returned as-is if the module path does not have a major
+// version suffix or the package is not part of the module.
+func unversionedPkgPath(pkgPath string, module *packages.Module) string {
+	if module == nil || pkgPath != module.Path && !strings.HasPrefix(pkgPath, module.Path+"/") {
+		return pkgPath
+	}
+	loc := majorVersionSuffix.FindStringIndex(module.Path)
+	if loc == nil {
+		return pkgPath
+	}
+	return module.Path[:loc[0]] + pkgPath[len(module.Path):]
+}
+
+// keyRegexp returns the regular expression that matches the entire keys matched by the provided rule name, or nil if
+// the name is not a valid regular expression. Names of rules that are loaded from configuration have already been
+// validated.
+func keyRegexp(name string) *regexp.Regexp {
+	if re, ok := keyRegexps.Load(name); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile("^(?:" + name + ")$")
+	if err != nil {
+		return nil
+	}
+	keyRegexps.Store(name, re)
+	return re
+}
+
+// canonicalKey returns the provided function key with the vendor directory prefix of the package path removed, so that
+// functions of vendored packages have the same key as the functions of the packages that were vendored. The leading
+// "*" of methods that are called on pointer receivers is also removed since a method is the same function regardless
+// of whether it is called on a value or on a pointer (a leading "*." is a wildcard of a glob pattern and is retained).
+func canonicalKey(key string) string {
+	if !strings.HasPrefix(key, "*.") {
+		key = strings.TrimPrefix(key, "*")
+	}
+	if idx := strings.LastIndex(key, "/"+vendorDir+"/"); idx != -1 {
+		key = key[idx+len(vendorDir)+2:]
+	} else {
+		key = strings.TrimPrefix(key, vendorDir+"/")
+	}
+	return key
+}
+
+// isGlobName returns true if the provided rule name is a glob pattern, which is the case if it contains a "*" other
+// than the leading "*" of a method that is called on a pointer receiver (such as "*encoding/json.Decoder.Decode"). A
+// leading "*" that is followed by "." (such as in "*.UnmarshalInto") is a wildcard.
+func isGlobName(name string) bool {
+	if strings.HasPrefix(name, "*") && !strings.HasPrefix(name, "*.") {
+		name = name[1:]
+	}
+	return strings.Contains(name, "*")
+}
+
+// globRegexp returns the regular expression for the provided glob pattern, in which "*" matches any sequence of
+// characters. Glob patterns match the entire name in the same manner as MatchExact, so "gopkg.in/yaml.v3.*" matches all
+// of the functions and methods of the package and "*.UnmarshalInto" matches the functions and methods with that name
+// in any package.
+func globRegexp(glob string) string {
+	parts := strings.Split(glob, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, ".*")
+}
+
+// stripTypeParams returns the provided rule name with the type parameter lists of generic functions and types, such as
+// the "[T]" of "example.com/codec.Unmarshal[T]", removed. Rules for generic functions match every instantiation.
+func stripTypeParams(name string) string {
+	if !strings.Contains(name, "[") {
+		return name
+	}
+	var b strings.Builder
+	depth := 0
+	for _, r := range name {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
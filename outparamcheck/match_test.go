@@ -0,0 +1,92 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestMatchesKey(t *testing.T) {
+	for i, tc := range []struct {
+		key  string
+		name string
+		mode MatchMode
+		want bool
+	}{
+		{"encoding/json.Unmarshal", "encoding/json.Unmarshal", MatchSuffix, true},
+		{"example.com/myjson.Unmarshal", "json.Unmarshal", MatchSuffix, false},
+		{"encoding/json.Unmarshal", "json.Unmarshal", MatchSuffix, true},
+		{"example.com/config.Load", ".Load", MatchSuffix, true},
+		{"*encoding/json.Decoder.Decode", "Decoder.Decode", MatchSuffix, true},
+		{"*encoding/json.Decoder.Decode", "*json.Decoder.Decode", MatchSuffix, true},
+		{"example.com/project/vendor/gopkg.in/yaml.v2.Unmarshal", "gopkg.in/yaml.v2.Unmarshal", MatchSuffix, true},
+		{"example.com/myjson.Unmarshal", "json.Unmarshal", MatchExact, false},
+		{"encoding/json.Unmarshal", "json.Unmarshal", MatchExact, false},
+		{"encoding/json.Unmarshal", "encoding/json.Unmarshal", MatchExact, true},
+		{"example.com/project/vendor/gopkg.in/yaml.v2.Unmarshal", "gopkg.in/yaml.v2.Unmarshal", MatchExact, true},
+		{"vendor/golang.org/x/net/foo.Unmarshal", "golang.org/x/net/foo.Unmarshal", MatchExact, true},
+		{"*encoding/json.Decoder.Decode", "encoding/json.Decoder.Decode", MatchExact, true},
+		{"encoding/json.Decoder.Decode", "*encoding/json.Decoder.Decode", MatchExact, true},
+		{"*example.com/json.Decoder.Decode", "encoding/json.Decoder.Decode", MatchExact, false},
+		{"*encoding/json.Decoder.Decode", `.*\.Decode`, MatchRegex, true},
+		{"*encoding/json.Decoder.DecodeAll", `.*\.Decode`, MatchRegex, false},
+		{"example.com/vendor/encoding/gob.Decoder.Decode", `encoding/.*\.Decode$`, MatchRegex, true},
+		{"encoding/json.Unmarshal", `(encoding/json`, MatchRegex, false},
+		{"gopkg.in/yaml.v3.Unmarshal", "gopkg.in/yaml.v3.*", MatchSuffix, true},
+		{"*gopkg.in/yaml.v3.Decoder.Decode", "gopkg.in/yaml.v3.*", MatchSuffix, true},
+		{"example.com/vendor/gopkg.in/yaml.v3.Unmarshal", "gopkg.in/yaml.v3.*", MatchExact, true},
+		{"gopkg.in/yaml.v2.Unmarshal", "gopkg.in/yaml.v3.*", MatchSuffix, false},
+		{"*example.com/config.Loader.UnmarshalInto", "*.UnmarshalInto", MatchSuffix, true},
+		{"example.com/config.UnmarshalInto", "*.UnmarshalInto", MatchSuffix, true},
+		{"example.com/config.UnmarshalIntoAll", "*.UnmarshalInto", MatchSuffix, false},
+		{"example.com/config.UnmarshalInto", "example.com/*.Unmarshal*", MatchSuffix, true},
+		{"example.com/config.UnmarshalInto", "*example.com/*.Unmarshal*", MatchSuffix, true},
+		{"example.com/codec.Unmarshal", "example.com/codec.Unmarshal[T]", MatchExact, true},
+		{"example.com/codec.Convert", "codec.Convert[A, B]", MatchSuffix, true},
+	} {
+		assert.Equal(t, tc.want, matchesKey(tc.key, tc.name, tc.mode), "Case %d: %s %s", i, tc.key, tc.name)
+	}
+}
+
+func TestMatchesCallPackages(t *testing.T) {
+	rule := &Rule{Args: indexArgs(0), Match: MatchRegex, Packages: []string{"encoding/..."}}
+	assert.True(t, matchesCall("*encoding/json.Decoder.Decode", "encoding/json", `.*\.Decode`, rule, rule.Match))
+	assert.True(t, matchesCall("*example.com/vendor/encoding/json.Decoder.Decode", "example.com/vendor/encoding/json", `.*\.Decode`, rule, rule.Match))
+	assert.False(t, matchesCall("*example.com/codec.Decoder.Decode", "example.com/codec", `.*\.Decode`, rule, rule.Match))
+}
+
+func TestUnversionedPkgPath(t *testing.T) {
+	for i, tc := range []struct {
+		pkgPath string
+		module  *packages.Module
+		want    string
+	}{
+		{"github.com/palantir/example/v2/codec", &packages.Module{Path: "github.com/palantir/example/v2"}, "github.com/palantir/example/codec"},
+		{"github.com/palantir/example/v12", &packages.Module{Path: "github.com/palantir/example/v12"}, "github.com/palantir/example"},
+		{"github.com/palantir/example/v1/codec", &packages.Module{Path: "github.com/palantir/example"}, "github.com/palantir/example/v1/codec"},
+		{"github.com/palantir/example/v2/codec", &packages.Module{Path: "github.com/palantir/example"}, "github.com/palantir/example/v2/codec"},
+		{"gopkg.in/yaml.v3", &packages.Module{Path: "gopkg.in/yaml.v3"}, "gopkg.in/yaml.v3"},
+		{"github.com/palantir/example/v2/codec", nil, "github.com/palantir/example/v2/codec"},
+	} {
+		assert.Equal(t, tc.want, unversionedPkgPath(tc.pkgPath, tc.module), "Case %d", i)
+	}
+}
+
+func TestVisitorMatchesCallMajorVersions(t *testing.T) {
+	v := &visitor{
+		modules: map[string]*packages.Module{
+			"github.com/palantir/example/v2/codec": {Path: "github.com/palantir/example/v2"},
+		},
+	}
+	rule := &Rule{Args: indexArgs(0)}
+	key, pkgPath := "*github.com/palantir/example/v2/codec.Decoder.Decode", "github.com/palantir/example/v2/codec"
+	assert.True(t, v.matchesCall(key, pkgPath, "github.com/palantir/example/codec.Decoder.Decode", rule, MatchExact))
+	assert.True(t, v.matchesCall(key, pkgPath, "github.com/palantir/example/v2/codec.Decoder.Decode", rule, MatchExact))
+	assert.False(t, v.matchesCall(key, pkgPath, "github.com/palantir/example/v3/codec.Decoder.Decode", rule, MatchExact))
+	assert.True(t, v.matchesCall(key, pkgPath, "example/codec.Decoder.Decode", rule, MatchSuffix))
+}
@@ -6,72 +6,324 @@
 package outparamcheck
 
 import (
-	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
+	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
 )
 
-func Run(cfgParam string, paths []string) error {
-	cfg := Config{}
-	if cfgParam != "" {
-		var usrCfg Config
-		var err error
-		if strings.HasPrefix(cfgParam, "@") {
-			usrCfg, err = loadCfgFromPath(cfgParam[1:])
-		} else {
-			usrCfg, err = loadCfg(cfgParam)
+// stdinCfgParam is the configuration parameter that specifies that the configuration should be read from standard
+// input.
+const stdinCfgParam = "@-"
+
+// stdin is the reader from which configuration is read when the configuration parameter is stdinCfgParam.
+var stdin io.Reader = os.Stdin
+
+// Options configures a run of the checker.
+type Options struct {
+	// Config is either empty, a JSON configuration, '@' followed by the path to a JSON configuration file or "@-" to
+	// read the JSON configuration from standard input.
+	Config string
+	// Preset is the name of the preset that provides the built-in configuration. The default preset is used if it is
+	// empty.
+	Preset string
+	// Baseline is the path of a baseline file written using WriteBaseline. The errors that are in the baseline are not
+	// reported.
+	Baseline string
+	// WriteBaseline is the path of the baseline file to which all of the errors are written instead of being reported.
+	WriteBaseline string
+	// Suppressions is the path of a suppression file, whose suppressions that have not expired suppress the errors that
+	// they match.
+	Suppressions string
+	// Allowlist is the path of an allowlist file written using WriteAllowlist. The errors whose fingerprints are in the
+	// allowlist are not reported.
+	Allowlist string
+	// WriteAllowlist is the path of the allowlist file to which the fingerprints of all of the errors are written instead
+	// of the errors being reported.
+	WriteAllowlist string
+	// DiffBase is a git revision, such as "origin/main". If it is not empty, only the errors on lines that were added or
+	// modified since the merge base of HEAD and the revision are reported.
+	DiffBase string
+	// SkipVendor specifies that the packages in vendor directories are not checked.
+	SkipVendor bool
+	// SkipTestdata specifies that the packages in testdata directories are not checked.
+	SkipTestdata bool
+	// SkipTests specifies that test files are not loaded or checked.
+	SkipTests bool
+	// SkipExternalTests specifies that the external test packages, whose package name has the suffix "_test", are not
+	// checked. The test files of the checked packages themselves are checked unless SkipTests is true.
+	SkipExternalTests bool
+	// SkipPackages are the patterns of the import paths of the packages that are not loaded or checked, which use the
+	// same syntax as the excludePackages of the configuration.
+	SkipPackages []string
+	// SkipFiles are the patterns of the files that are not checked, which are either regular expressions or glob
+	// patterns.
+	SkipFiles []string
+	// InferWrappers specifies that the functions of the checked packages that pass one of their parameters directly to
+	// an output parameter, possibly through other such functions, are checked as if their parameter was an output
+	// parameter.
+	InferWrappers bool
+	// WriteWrapperRules is the path of the configuration file to which rules for the exported wrappers that are inferred
+	// from the checked packages are written, which other modules can extend. The rules are written even if
+	// InferWrappers is false, in which case the wrappers are not used to check the packages.
+	WriteWrapperRules string
+	// Mode is the analysis mode, which is either ModeAST (the default if it is empty) or ModeSSA.
+	Mode string
+	// MaxIssues is the maximum number of errors that may be reported without failing. It is ignored if Ceilings is not
+	// empty.
+	MaxIssues int
+	// Ceilings is the path of a file of the maximum numbers of errors that the packages may have without failing.
+	Ceilings string
+	// UpdateCeilings specifies that the ceilings of the Ceilings file are lowered to the numbers of errors of the checked
+	// packages, or that the file is created with the numbers of errors of the checked packages if it does not exist.
+	UpdateCeilings bool
+	// Stdin specifies that the source of a single Go file is read from standard input and checked instead of packages,
+	// which allows editors to check unsaved buffers.
+	Stdin bool
+	// PackagePath is the path of the Go file that the source read from standard input is checked as, or the directory of
+	// the package to which the source is added as a new file. It is required if Stdin is true.
+	PackagePath string
+	// Checks are the names of the optional subchecks that are run in addition to the check of output parameters.
+	Checks []string
+	// Strict specifies that the explicit ways of passing an output parameter without '&', which are *&x and the literal
+	// nil, are also reported, so that every such argument requires a suppression directive.
+	Strict bool
+}
+
+// skippedDirs returns the names of the directories whose packages are not checked.
+func (o Options) skippedDirs() []string {
+	var dirs []string
+	if o.SkipVendor {
+		dirs = append(dirs, vendorDir)
+	}
+	if o.SkipTestdata {
+		dirs = append(dirs, testdataDir)
+	}
+	return dirs
+}
+
+func Run(opts Options, paths []string) error {
+	if opts.Stdin && opts.Config == stdinCfgParam {
+		return errors.Errorf("the configuration and the source cannot both be read from standard input")
+	}
+	cfg, err := LoadConfig(opts.Config, opts.Preset)
+	if err != nil {
+		return err
+	}
+	skipFiles, err := newFileFilter(opts.SkipFiles)
+	if err != nil {
+		return err
+	}
+	if err := validateMode(opts.Mode); err != nil {
+		return err
+	}
+	checks, err := validateChecks(opts.Checks)
+	if err != nil {
+		return err
+	}
+	fileArgs, err := newGoFileArgs(paths)
+	if err != nil {
+		return err
+	}
+	var src stdinSource
+	if opts.Stdin {
+		if len(paths) > 0 {
+			return errors.Errorf("packages cannot be specified when the source is read from standard input")
 		}
+		if src, err = readStdinSource(opts.PackagePath); err != nil {
+			return err
+		}
+		paths = []string{src.pattern}
+		fileArgs = goFiles{src.filename: true}
+	}
+
+	pkgs, err := load(paths, opts.skippedDirs(), opts.SkipPackages, opts.testsMode(), src.overlay)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	pkgCfgs, err := packageConfigs(pkgs, cfg)
+	if err != nil {
+		return err
+	}
+	pkgCfg := func(pkg *packages.Package) Config {
+		return pkgCfgs[pkg]
+	}
+	a := analysis{overlay: src.overlay, checks: checks, strict: opts.Strict}
+	if opts.Mode == ModeSSA {
+		a.ssaCalls = buildSSACalls(pkgs)
+	}
+	if opts.InferWrappers || opts.WriteWrapperRules != "" {
+		w := inferWrappers(pkgs, pkgCfg)
+		if opts.WriteWrapperRules != "" && len(pkgs) > 0 {
+			if err := writeWrapperRules(opts.WriteWrapperRules, w, pkgs[0].Fset); err != nil {
+				return err
+			}
+		}
+		if opts.InferWrappers {
+			a.wrappers = w
+		}
+	}
+	errs := runWithConfigs(pkgs, pkgCfg, skipFiles, a)
+	errs = fileArgs.filter(errs)
+	if opts.Suppressions != "" {
+		suppressions, err := readSuppressions(opts.Suppressions)
 		if err != nil {
-			return errors.Wrapf(err, "Failed to load configuration from parameter %s", cfgParam)
+			return err
 		}
-		for key, val := range usrCfg {
-			cfg[key] = val
+		errs = filterSuppressed(errs, suppressions)
+	}
+	if opts.WriteAllowlist != "" {
+		return writeAllowlist(opts.WriteAllowlist, errs)
+	}
+	if opts.Allowlist != "" {
+		a, err := readAllowlist(opts.Allowlist)
+		if err != nil {
+			return err
 		}
+		errs = a.filter(errs)
 	}
-	// add default config (values for default will override any user-supplied config for the same keys)
-	for key, val := range defaultCfg {
-		cfg[key] = val
+	if opts.WriteBaseline != "" {
+		return writeBaseline(opts.WriteBaseline, newBaseline(errs))
 	}
+	if opts.Baseline != "" {
+		b, err := readBaseline(opts.Baseline)
+		if err != nil {
+			return err
+		}
+		errs = b.filter(errs)
+	}
+	if opts.DiffBase != "" {
+		changed, err := changedLinesSince(".", opts.DiffBase)
+		if err != nil {
+			return err
+		}
+		errs = changed.filter(errs)
+	}
+	reportErrors(errs)
+	if opts.Ceilings != "" {
+		err := checkCeilings(opts.Ceilings, opts.UpdateCeilings, countByPackage(pkgs, errs))
+		if err != nil && requiresAddr(errs) {
+			return fmt.Errorf("%v\n%s", err, addrHint)
+		}
+		return err
+	}
+	if numErrs := countSeverity(errs, SeverityError); numErrs > opts.MaxIssues {
+		summary := plural(numErrs, "error", "errors")
+		if opts.MaxIssues > 0 {
+			summary = fmt.Sprintf("%s exceed the maximum of %d", summary, opts.MaxIssues)
+		}
+		if requiresAddr(errs) {
+			summary += "; " + addrHint
+		}
+		return fmt.Errorf("%s", summary)
+	}
+	return nil
+}
+
+// addrHint is the hint that is appended to the summary of the errors if output parameters were reported for not being
+// passed using '&'. The errors of subchecks and the other problems of output parameters are not fixed by using '&'.
+const addrHint = "the parameters listed above require the use of '&', for example f(&x) instead of f(x)"
+
+// requiresAddr returns true if any of the provided errors of error severity is an output parameter that is not passed
+// using '&'.
+func requiresAddr(errs []OutParamError) bool {
+	for _, err := range errs {
+		if err.Severity == SeverityError && err.Check == "" && err.Problem == "" {
+			return true
+		}
+	}
+	return false
+}
 
-	pkgs, err := load(paths)
+// checkCeilings returns an error if any of the provided numbers of errors of packages exceeds its ceiling in the
+// ceilings file with the provided path, which is first updated if update is true.
+func checkCeilings(ceilingsPath string, update bool, counts map[string]int) error {
+	c, err := readCeilings(ceilingsPath, update)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
+	}
+	if update {
+		c = c.ratchet(counts)
+		if err := writeCeilings(ceilingsPath, c); err != nil {
+			return err
+		}
 	}
-	errs := run(pkgs, cfg)
-	if len(errs) > 0 {
-		reportErrors(errs)
-		return fmt.Errorf("%s; the parameters listed above require the use of '&', for example f(&x) instead of f(x)",
-			plural(len(errs), "error", "errors"))
+	if exceeded := c.exceeded(counts); len(exceeded) > 0 {
+		return fmt.Errorf("the errors of %s exceed their ceilings:\n\t%s",
+			plural(len(exceeded), "package", "packages"), strings.Join(exceeded, "\n\t"))
 	}
 	return nil
 }
 
 func run(pkgs []*packages.Package, cfg Config) []OutParamError {
+	return runWithConfigs(pkgs, func(*packages.Package) Config {
+		return cfg
+	}, nil, analysis{})
+}
+
+// analysis is the information about the checked packages that is computed before they are checked.
+type analysis struct {
+	// ssaCalls are the calls of the SSA form of the packages, which are nil unless the SSA mode is used.
+	ssaCalls ssaCalls
+	// wrappers are the wrapper functions of the packages, which are nil unless wrappers are inferred.
+	wrappers *wrappers
+	// overlay are the contents of the files that replace their contents on disk, which are nil unless the source is
+	// read from standard input.
+	overlay map[string][]byte
+	// checks are the names of the optional subchecks that are run.
+	checks map[string]bool
+	// strict is true if *&x and the literal nil are reported.
+	strict bool
+}
+
+// runWithConfigs checks the provided packages, each using the configuration returned for it by pkgCfg. The files that
+// match skipFiles and the files that are generated by cgo are not checked.
+func runWithConfigs(pkgs []*packages.Package, pkgCfg func(pkg *packages.Package) Config, skipFiles *fileFilter, a analysis) []OutParamError {
 	var errs []OutParamError
 	var mut sync.Mutex // guards errs
 	var wg sync.WaitGroup
+	// the files of a package are also files of its test variant, whose directives are only checked once
+	directiveFiles := &sync.Map{}
 	for _, pkg := range pkgs {
+		cfg := pkgCfg(pkg)
+		if cfg.excludesPackage(pkg.PkgPath) {
+			continue
+		}
 		wg.Add(1)
 
 		go func(pkg *packages.Package) {
 			defer wg.Done()
 			v := &visitor{
-				pkg:    pkg,
-				lines:  map[string][]string{},
-				errors: []OutParamError{},
-				cfg:    cfg,
+				pkg:            pkg,
+				lines:          map[string][]string{},
+				errors:         []OutParamError{},
+				cfg:            cfg,
+				skipFiles:      skipFiles,
+				ssaCalls:       a.ssaCalls,
+				wrappers:       a.wrappers,
+				overlay:        a.overlay,
+				checks:         a.checks,
+				strict:         a.strict,
+				directiveFiles: directiveFiles,
 			}
 			for _, astFile := range v.pkg.Syntax {
+				if isCgoGenerated(v.pkg, astFile) || skipFiles.matches(v.pkg.Fset.Position(astFile.Pos()).Filename) {
+					continue
+				}
 				ast.Walk(v, astFile)
+				v.runSubchecks(astFile)
+				v.reportInvalidDirectives(astFile)
 			}
 			mut.Lock()
 			defer mut.Unlock()
@@ -82,31 +334,164 @@ func run(pkgs []*packages.Package, cfg Config) []OutParamError {
 	return errs
 }
 
+// LoadConfig returns the effective configuration for the provided configuration parameter, which is either empty, a
+// JSON configuration, '@' followed by the path to a JSON configuration file or "@-" to read the JSON configuration from
+// standard input. The returned configuration contains the configuration of the provided preset (or of the default
+// preset if it is empty) merged with the user-supplied configuration, whose rules take precedence over the rules of the
+// preset in the same way that configuration files take precedence over the configuration of their parent directories.
+func LoadConfig(cfgParam, preset string) (Config, error) {
+	builtinCfg, err := presetCfg(preset)
+	if err != nil {
+		return Config{}, err
+	}
+
+	usrCfg, err := loadUserConfig(cfgParam)
+	if err != nil {
+		return Config{}, err
+	}
+	// add user-supplied config (rules for user-supplied config override or remove the built-in rules for the same keys)
+	return builtinCfg.Merge(usrCfg), nil
+}
+
+// loadUserConfig returns the user-supplied configuration for the provided configuration parameter.
+func loadUserConfig(cfgParam string) (Config, error) {
+	if cfgParam == "" {
+		return Config{}, nil
+	}
+	var usrCfg Config
+	var err error
+	if cfgParam == stdinCfgParam {
+		usrCfg, err = loadCfgFromReader(stdin)
+	} else if strings.HasPrefix(cfgParam, "@") {
+		usrCfg, err = loadCfgFromPath(cfgParam[1:])
+	} else {
+		usrCfg, err = loadCfgFromJSON(cfgParam)
+	}
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "Failed to load configuration from parameter %s", cfgParam)
+	}
+	return usrCfg, nil
+}
+
+// packageConfigs returns the configuration for each of the provided packages, which is the provided configuration
+// with the configuration files in the working directory and in the directories between it and the package directory
+// applied on top of it.
+func packageConfigs(pkgs []*packages.Package, cfg Config) (map[*packages.Package]Config, error) {
+	dirCfgs, err := newDirConfigs(".", cfg)
+	if err != nil {
+		return nil, err
+	}
+	pkgCfgs := make(map[*packages.Package]Config, len(pkgs))
+	for _, pkg := range pkgs {
+		pkgCfgs[pkg] = cfg
+		if len(pkg.GoFiles) == 0 {
+			continue
+		}
+		pkgCfg, err := dirCfgs.forDir(filepath.Dir(pkg.GoFiles[0]))
+		if err != nil {
+			return nil, err
+		}
+		pkgCfgs[pkg] = pkgCfg
+	}
+	return pkgCfgs, nil
+}
+
+func loadCfgFromReader(r io.Reader) (Config, error) {
+	cfgBytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "failed to read standard input")
+	}
+	return loadCfgFromJSON(string(cfgBytes))
+}
+
 func loadCfgFromPath(cfgPath string) (Config, error) {
-	cfgBytes, err := ioutil.ReadFile(cfgPath)
+	return loadCfgFile(cfgPath, nil)
+}
+
+// loadCfgFromJSON loads the provided configuration and resolves the configurations that it extends relative to the
+// working directory.
+func loadCfgFromJSON(cfgJSON string) (Config, error) {
+	cfg, err := loadCfg(cfgJSON)
+	if err != nil {
+		return Config{}, err
+	}
+	wd, err := os.Getwd()
 	if err != nil {
-		return Config{}, errors.Wrapf(err, "failed to read file %s", cfgPath)
+		return Config{}, errors.Wrapf(err, "failed to determine working directory")
 	}
-	return loadCfg(string(cfgBytes))
+	return resolveExtends(cfg, wd, nil)
 }
 
 func loadCfg(cfgJSON string) (Config, error) {
-	var cfg Config
-	if err := json.Unmarshal([]byte(cfgJSON), &cfg); err != nil {
-		return Config{}, errors.Wrapf(err, "failed to unmarshal json %s", cfgJSON)
+	cfg, err := parseCfg(cfgJSON)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "invalid configuration %s", cfgJSON)
 	}
 	return cfg, nil
 }
 
-func load(paths []string) ([]*packages.Package, error) {
+// testsMode specifies which test files are loaded.
+type testsMode int
+
+const (
+	// testsAll loads the test files of the packages and their external test packages.
+	testsAll testsMode = iota
+	// testsInternal loads the test files of the packages but not their external test packages.
+	testsInternal
+	// testsNone does not load test files.
+	testsNone
+)
+
+// testsMode returns the test files that are loaded for the options.
+func (o Options) testsMode() testsMode {
+	switch {
+	case o.SkipTests:
+		return testsNone
+	case o.SkipExternalTests:
+		return testsInternal
+	default:
+		return testsAll
+	}
+}
+
+// load loads the packages that match the provided patterns other than the packages in directories with the provided
+// names, which are excluded before the packages are checked for errors, and the packages whose import paths match the
+// provided package patterns, which are excluded before the packages are loaded. A pattern that is the path of a Go file
+// loads the packages that contain the file. The contents of the files in the provided overlay replace their contents on
+// disk.
+func load(paths []string, skippedDirs, skippedPkgs []string, tests testsMode, overlay map[string][]byte) ([]*packages.Package, error) {
+	paths, err := goFileQueries(paths)
+	if err != nil {
+		return nil, err
+	}
+	paths, err = expandWorkspacePatterns(paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(skippedPkgs) > 0 {
+		paths, err = excludePackagePaths(paths, skippedPkgs)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, nil
+		}
+	}
 	cfg := &packages.Config{
-		Mode:  packages.LoadAllSyntax,
-		Tests: true,
+		Mode:    packages.LoadAllSyntax | packages.NeedModule,
+		Tests:   tests != testsNone,
+		Overlay: overlay,
 	}
 	pkgs, err := packages.Load(cfg, paths...)
 	if err != nil {
 		return nil, err
 	}
+	if tests == testsInternal {
+		pkgs = excludeExternalTests(pkgs)
+	}
+	for _, dir := range skippedDirs {
+		pkgs = excludePackagesInDir(pkgs, dir)
+	}
 	// check for errors in the initial packages
 	for _, pkg := range pkgs {
 		if len(pkg.Errors) > 0 {
@@ -121,99 +506,528 @@ type visitor struct {
 	lines  map[string][]string
 	errors []OutParamError
 	cfg    Config
+	// ifaces caches the interfaces whose methods are named by rules, keyed by rule name and matching mode.
+	ifaces map[string][]*types.Interface
+	// deps are the checked package and the packages that it depends on, which are computed when first needed.
+	deps []*types.Package
+	// bindings are the variables of the checked package that are bound to a function, which are computed when first
+	// needed.
+	bindings map[*types.Var]ast.Expr
+	// modules are the modules that contain the checked package and its dependencies keyed by the import paths of the
+	// packages, which are computed when first needed.
+	modules map[string]*packages.Module
+	// assignments are the assignments of the variables of the checked package, which are computed when first needed.
+	assignments *varAssignments
+	// fileDirectives are the directives of the files of the checked package keyed by file name, which are computed when
+	// first needed.
+	fileDirectives map[string]*fileDirectives
+	// directiveFiles are the names of the files whose directives have been checked, which are shared by the visitors of
+	// the checked packages so that the directives of a file are reported once. Every file is checked if it is nil.
+	directiveFiles *sync.Map // map[string]bool
+	// skipFiles matches the files whose errors are not reported.
+	skipFiles *fileFilter
+	// wrappers are the functions that pass their parameters to output parameters, which are nil unless wrappers are
+	// inferred.
+	wrappers *wrappers
+	// ssaCalls are the calls of the SSA form of the checked packages, which are nil unless the SSA mode is used.
+	ssaCalls ssaCalls
+	// overlay are the contents of the files that replace their contents on disk keyed by file name.
+	overlay map[string][]byte
+	// checks are the names of the optional subchecks that are run.
+	checks map[string]bool
+	// strict is true if *&x and the literal nil are reported.
+	strict bool
 }
 
 func (v *visitor) Visit(node ast.Node) ast.Visitor {
-	switch stmt := node.(type) {
-	case *ast.AssignStmt:
-		for _, expr := range stmt.Rhs {
-			v.processExpression(expr)
-		}
-	case *ast.GoStmt:
-		v.processExpression(stmt.Call)
-	case *ast.DeferStmt:
-		v.processExpression(stmt.Call)
-	case *ast.SendStmt:
-		v.processExpression(stmt.Value)
-	case *ast.ReturnStmt:
-		for _, expr := range stmt.Results {
-			v.processExpression(expr)
-		}
-	case *ast.SwitchStmt:
-		for _, stmt := range stmt.Body.List {
-			if caseClauseStmt, ok := stmt.(*ast.CaseClause); ok {
-				for _, expr := range caseClauseStmt.List {
-					v.processExpression(expr)
-				}
+	// every call is checked wherever it appears, including the arguments of other calls, the conditions of statements,
+	// the communication clauses of select statements and the initializers of declarations
+	if call, ok := node.(*ast.CallExpr); ok {
+		v.processCall(call)
+	}
+	return v
+}
+
+func (v *visitor) processCall(call *ast.CallExpr) {
+	method, outArgs, missing := v.callArgs(call)
+
+	for _, arg := range missing {
+		v.errorAt(call.Pos(), method, arg.rule, arg.index, arg.severity, fmt.Sprintf(missingArgProblem, arg.rule, arg.numParams))
+	}
+
+	indices := make([]int, 0, len(outArgs))
+	for i := range outArgs {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	for _, i := range indices {
+		arg, out := call.Args[i], outArgs[i]
+		if v.wrappers.forwards(arg.Pos()) {
+			continue
+		}
+		if (out.disallowNil || v.strict) && v.isNil(arg) {
+			v.errorAt(arg.Pos(), method, out.rule, i, out.severity, nilProblem)
+			continue
+		}
+		if v.strict && isDerefAddr(arg) {
+			v.errorAt(arg.Pos(), method, out.rule, i, out.severity, derefAddrProblem)
+			continue
+		}
+		typ := v.pkg.TypesInfo.TypeOf(arg)
+		if isReflectValue(typ) {
+			if inner := v.reflectValueOfArg(arg); inner != nil && !v.isAddr(inner) && !isRef(v.pkg.TypesInfo.TypeOf(inner), out.kind) {
+				v.errorAt(arg.Pos(), method, out.rule, i, out.severity, reflectValueProblem)
 			}
+			continue
+		}
+		// arguments whose type is a pointer are accepted even if they are not passed using '&'
+		if !v.isArgAddr(call, i) && !isRef(typ, out.kind) {
+			v.errorAt(arg.Pos(), method, out.rule, i, out.severity, v.addrProblem(arg))
+		} else if !out.kind.matches(typ) {
+			v.errorAt(arg.Pos(), method, out.rule, i, out.severity, fmt.Sprintf("must be %s, was %s", argKindDescriptions[out.kind], typ))
+		} else if problem := v.errorsAsTargetProblem(call, i); problem != "" {
+			v.errorAt(arg.Pos(), method, out.rule, i, out.severity, problem)
 		}
-	case *ast.ExprStmt:
-		v.processExpression(stmt.X)
 	}
-	return v
 }
 
-func (v *visitor) processExpression(expr ast.Expr) {
-	switch expr := expr.(type) {
-	case *ast.BinaryExpr:
-		v.processExpression(expr.X)
-		v.processExpression(expr.Y)
-	case *ast.KeyValueExpr:
-		v.processExpression(expr.Value)
-	case *ast.CompositeLit:
-		for _, subExpr := range expr.Elts {
-			v.processExpression(subExpr)
+// missingArgProblem is the format of the problem that is reported for calls of functions that do not have an argument
+// that a rule refers to, which typically means that the function has changed since the rule was written.
+const missingArgProblem = "does not exist since the function has %[2]d parameters; the rule %[1]q may be out of date"
+
+// nilProblem is the problem that is reported for the literal nil if the rule of the output parameter disallows it.
+const nilProblem = "must not be nil"
+
+// derefAddrProblem is the problem that is reported for *&x in strict mode.
+const derefAddrProblem = "passes a copy using *&; suppress the error with a directive if a copy is intended"
+
+// isDerefAddr returns true if the provided expression dereferences an address, such as *&x, which is the explicit way
+// to pass a value to an output parameter.
+func isDerefAddr(expr ast.Expr) bool {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	child, ok := star.X.(*ast.UnaryExpr)
+	return ok && child.Op == token.AND
+}
+
+// isNil returns true if the provided expression is the literal nil.
+func (v *visitor) isNil(expr ast.Expr) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, isNil := v.pkg.TypesInfo.Uses[ident].(*types.Nil)
+	return isNil
+}
+
+// notAddressableProblem is the problem that is reported for arguments whose address cannot be taken.
+const notAddressableProblem = "is not addressable; assign it to a variable first, then pass its address"
+
+// addrProblem returns the problem that is reported for the provided argument, which is not passed using '&'. Taking the
+// address of arguments that are not addressable, such as map index expressions and the results of function calls, is
+// not possible, so a temporary variable is required for them. An argument that dereferences a pointer, such as *p,
+// passes a copy of the value that the pointer points to, so the pointer itself should be passed instead.
+func (v *visitor) addrProblem(arg ast.Expr) string {
+	if star, ok := ast.Unparen(arg).(*ast.StarExpr); ok && isPointer(v.pkg.TypesInfo.TypeOf(star.X)) {
+		ptr := types.ExprString(star.X)
+		return fmt.Sprintf("dereferences %s, so the value is decoded into a copy and lost; pass %s instead", ptr, ptr)
+	}
+	tv, ok := v.pkg.TypesInfo.Types[arg]
+	if !ok || tv.Addressable() {
+		return ""
+	}
+	// the address of a composite literal can be taken although it is not addressable
+	if _, ok := ast.Unparen(arg).(*ast.CompositeLit); ok {
+		return ""
+	}
+	return notAddressableProblem
+}
+
+// excludesCall returns true if an exclusion rule matches the function with the provided key, which is defined in the
+// package with the provided import path.
+func (v *visitor) excludesCall(key, pkgPath string) bool {
+	for name, rule := range v.cfg.Rules {
+		if !rule.Exclude {
+			continue
 		}
-	case *ast.CallExpr:
-		call := expr
-		key, method, ok := v.keyAndName(call)
+		name = resolveModuleRelative(strings.TrimPrefix(name, exclusionPrefix), v.pkg.Module)
+		if v.matchesCall(key, pkgPath, name, rule, v.cfg.matchMode(rule)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCall returns true if the provided rule with the provided name matches the called function with the provided
+// key, which is defined in the package with the provided import path, using the provided matching mode. Rules that
+// name the function without the major version suffix of the module that defines it, such as
+// "github.com/palantir/example/codec.Unmarshal" for "github.com/palantir/example/v2/codec.Unmarshal", also match.
+func (v *visitor) matchesCall(key, pkgPath, name string, rule *Rule, mode MatchMode) bool {
+	if matchesCall(key, pkgPath, name, rule, mode) {
+		return true
+	}
+	unversioned := unversionedPkgPath(pkgPath, v.packageModule(pkgPath))
+	return unversioned != pkgPath && matchesCall(strings.Replace(key, pkgPath, unversioned, 1), unversioned, name, rule, mode)
+}
+
+// packageModule returns the module that contains the package with the provided import path, which is either the checked
+// package or one of its dependencies, or nil if it is not known.
+func (v *visitor) packageModule(pkgPath string) *packages.Module {
+	if v.modules == nil {
+		v.modules = make(map[string]*packages.Module)
+		packages.Visit([]*packages.Package{v.pkg}, nil, func(pkg *packages.Package) {
+			v.modules[pkg.PkgPath] = pkg.Module
+		})
+	}
+	return v.modules[pkgPath]
+}
+
+// callOutArgs returns the name of the function or method that the provided call calls along with the output
+// parameters of the call keyed by argument index.
+func (v *visitor) callOutArgs(call *ast.CallExpr) (string, map[int]*outArg) {
+	method, outArgs, _ := v.callArgs(call)
+	return method, outArgs
+}
+
+// callArgs returns the name of the function or method that the provided call calls, the output parameters of the call
+// keyed by argument index and the arguments that rules naming the function refer to but that the function does not
+// have.
+func (v *visitor) callArgs(call *ast.CallExpr) (string, map[int]*outArg, []missingArg) {
+	key, pkgPath, method, ok := v.keyAndName(call)
+	if !ok {
+		return "", nil, nil
+	}
+	// the receiver of a method expression call, such as (*json.Decoder).Decode(dec, &x), is passed as the first
+	// argument, which precedes the arguments that the indices of rules refer to
+	sig, _ := v.pkg.TypesInfo.TypeOf(call.Fun).(*types.Signature)
+	numArgs, recvArgs := len(call.Args), 0
+	if methodSig := v.methodExprSignature(call); methodSig != nil && numArgs > 0 {
+		sig, numArgs, recvArgs = methodSig, numArgs-1, 1
+	}
+	// the output parameters of the call; errors take precedence over warnings if several rules match the call
+	outArgs := make(map[int]*outArg)
+	addOutArg := func(i int, rule string, severity Severity, kind ArgKind, allowNil bool) {
+		i += recvArgs
+		if i >= len(call.Args) {
+			// rules that match several functions may refer to arguments that some of them do not have, and a call
+			// whose only argument is a call with several results, such as json.Unmarshal(read()), has fewer arguments
+			// than parameters
+			return
+		}
+		if call.Ellipsis.IsValid() && i == len(call.Args)-1 {
+			// the last argument of a call such as f(args...) is a slice whose elements cannot be inspected
+			return
+		}
+		curr, ok := outArgs[i]
 		if !ok {
+			outArgs[i] = &outArg{rule: rule, severity: severity, kind: kind, disallowNil: !allowNil}
 			return
 		}
-		for name, outs := range v.cfg {
-			// Suffix-matching so they also apply to vendored packages
-			if strings.HasSuffix(key, name) {
-				for _, i := range outs {
-					arg := call.Args[i]
-					if !isAddr(arg) {
-						v.errorAt(arg.Pos(), method, i)
-					}
+		if rule != "" && (curr.rule == "" || rule < curr.rule) {
+			curr.rule = rule
+		}
+		if severity < curr.severity {
+			curr.severity = severity
+		}
+		if !allowNil {
+			curr.disallowNil = true
+		}
+		if curr.kind == ArgKindAny {
+			curr.kind = kind
+		}
+	}
+	if v.excludesCall(key, pkgPath) {
+		return "", nil, nil
+	}
+	var missing []missingArg
+	for name, rule := range v.cfg.Rules {
+		if rule.Exclude {
+			continue
+		}
+		name = resolveModuleRelative(name, v.pkg.Module)
+		mode := v.cfg.matchMode(rule)
+		if v.matchesCall(key, pkgPath, name, rule, mode) || v.matchesImplementer(call, pkgPath, name, rule, mode) {
+			missing = append(missing, missingArgs(name, rule, mode, sig)...)
+			for _, arg := range rule.outArgs() {
+				for _, i := range arg.indices(numArgs, sig) {
+					addOutArg(i, name, rule.Severity, arg.Kind, rule.allowsNil())
 				}
 			}
 		}
 	}
+	if fn := v.calleeFunc(call); fn != nil {
+		for _, rule := range v.cfg.Signatures {
+			if outParams, ok := rule.outParams(fn); ok {
+				for _, i := range outParams {
+					addOutArg(i, "", rule.Severity, ArgKindAny, true)
+				}
+			}
+		}
+		for i, out := range v.wrappers.params(fn.Origin()) {
+			addOutArg(i, out.rule, out.severity, out.kind, !out.disallowNil)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].index < missing[j].index
+	})
+	return method, outArgs, missing
+}
+
+// missingArg is an argument that a rule refers to but that the function that the rule names does not have.
+type missingArg struct {
+	index     int
+	severity  Severity
+	rule      string
+	numParams int
+}
+
+// missingArgs returns the arguments of the provided rule with the provided name that a function with the provided
+// signature does not have. Only rules that name a single function are considered, since rules that match several
+// functions, such as regular expressions and glob patterns, may refer to arguments that some of them do not have.
+// Variadic functions have any number of arguments, and indices that count from the last argument are not reported.
+func missingArgs(name string, rule *Rule, mode MatchMode, sig *types.Signature) []missingArg {
+	if sig == nil || sig.Variadic() || mode != MatchSuffix && mode != MatchExact || isGlobName(name) {
+		return nil
+	}
+	var missing []missingArg
+	for _, arg := range rule.Args {
+		if arg.EmptyInterfaces || arg.Index < 0 || arg.Index < sig.Params().Len() {
+			continue
+		}
+		missing = append(missing, missingArg{index: arg.Index, severity: rule.Severity, rule: name, numParams: sig.Params().Len()})
+	}
+	return missing
+}
+
+// outArg is an output parameter of a call.
+type outArg struct {
+	// rule is the name of the rule that configures the output parameter, which is the first in lexical order if
+	// several rules do and empty if only signature rules do.
+	rule     string
+	severity Severity
+	kind     ArgKind
+	// disallowNil is true if the output parameter may not be passed the literal nil.
+	disallowNil bool
+}
+
+// methodExprSignature returns the signature of the method if the provided call is a call of a method expression, such
+// as (*json.Decoder).Decode(dec, &x), and nil otherwise.
+func (v *visitor) methodExprSignature(call *ast.CallExpr) *types.Signature {
+	target, ok := v.callTarget(call).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	sel, ok := v.pkg.TypesInfo.Selections[target]
+	if !ok || sel.Kind() != types.MethodExpr {
+		return nil
+	}
+	return sel.Obj().Type().(*types.Signature)
+}
+
+// callTarget returns the expression that refers to the function called by the provided call. Parentheses, conversions
+// to function types and explicit instantiations of generic functions, such as Unmarshal[Config] and Convert[A, B], are
+// unwrapped and calls through variables that are bound to a function, such as u in "u := json.Unmarshal", are resolved
+// to the function.
+func (v *visitor) callTarget(call *ast.CallExpr) ast.Expr {
+	target := v.unwrapped(call.Fun)
+	seen := make(map[*types.Var]bool)
+	for {
+		ident, ok := target.(*ast.Ident)
+		if !ok {
+			return target
+		}
+		obj, ok := v.pkg.TypesInfo.Uses[ident].(*types.Var)
+		if !ok || seen[obj] {
+			return target
+		}
+		seen[obj] = true
+		bound := v.boundFunc(obj)
+		if bound == nil {
+			return target
+		}
+		target = v.unwrapped(bound)
+	}
+}
+
+// unwrapped returns the function that the provided expression refers to without the parentheses, conversions to
+// function types and explicit instantiations that wrap it, such as json.Unmarshal for "(json.Unmarshal)" or
+// "unmarshalFunc(json.Unmarshal)". The expression itself is returned if it is not wrapped.
+func (v *visitor) unwrapped(expr ast.Expr) ast.Expr {
+	for {
+		switch wrapper := expr.(type) {
+		case *ast.ParenExpr:
+			expr = wrapper.X
+		case *ast.CallExpr:
+			if !v.isFuncConversion(wrapper) {
+				return expr
+			}
+			expr = wrapper.Args[0]
+		case *ast.IndexExpr, *ast.IndexListExpr:
+			target := v.uninstantiated(expr)
+			if target == expr {
+				return expr
+			}
+			expr = target
+		default:
+			return expr
+		}
+	}
+}
+
+// isFuncConversion returns true if the provided call is a conversion to a function type.
+func (v *visitor) isFuncConversion(call *ast.CallExpr) bool {
+	if len(call.Args) != 1 {
+		return false
+	}
+	tv, ok := v.pkg.TypesInfo.Types[call.Fun]
+	if !ok || !tv.IsType() {
+		return false
+	}
+	_, isFunc := tv.Type.Underlying().(*types.Signature)
+	return isFunc
+}
+
+// uninstantiated returns the generic function of the provided expression if it is an explicit instantiation and the
+// expression itself otherwise.
+func (v *visitor) uninstantiated(expr ast.Expr) ast.Expr {
+	var target ast.Expr
+	var indices []ast.Expr
+	switch expr := expr.(type) {
+	case *ast.IndexExpr:
+		target, indices = expr.X, []ast.Expr{expr.Index}
+	case *ast.IndexListExpr:
+		target, indices = expr.X, expr.Indices
+	default:
+		return expr
+	}
+	// the index expression is an instantiation only if its indices are types; otherwise, it is an element of a slice or
+	// map of functions
+	for _, index := range indices {
+		if tv, ok := v.pkg.TypesInfo.Types[index]; !ok || !tv.IsType() {
+			return expr
+		}
+	}
+	return target
+}
+
+// calleeFunc returns the function or method called by the provided call, or nil if the callee is not a function or
+// method that is referred to by name.
+func (v *visitor) calleeFunc(call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch target := v.callTarget(call).(type) {
+	case *ast.Ident:
+		ident = target
+	case *ast.SelectorExpr:
+		ident = target.Sel
+	}
+	if ident == nil {
+		return nil
+	}
+	fn, _ := v.pkg.TypesInfo.Uses[ident].(*types.Func)
+	return fn
 }
 
-func (v *visitor) keyAndName(call *ast.CallExpr) (key string, name string, ok bool) {
-	switch target := call.Fun.(type) {
+// keyAndName returns the key of the function called by the provided call, the import path of the package in which the
+// function is defined (which is empty if it is not known) and the name of the function.
+func (v *visitor) keyAndName(call *ast.CallExpr) (key string, pkgPath string, name string, ok bool) {
+	switch target := v.callTarget(call).(type) {
 	case *ast.Ident:
 		// Function calls without a selector; this includes calls within the
 		// same package as well as calls into dot-imported packages
 		if def, ok := v.pkg.TypesInfo.Uses[target]; ok && def.Pkg() != nil {
-			return fmt.Sprintf("%v.%v", def.Pkg().Path(), target.Name), target.Name, true
+			return fmt.Sprintf("%v.%v", def.Pkg().Path(), target.Name), def.Pkg().Path(), target.Name, true
 		}
 	case *ast.SelectorExpr:
 		// Function calls into other packages
 		if recv, ok := target.X.(*ast.Ident); ok {
 			if pkg, ok := v.pkg.TypesInfo.Uses[recv].(*types.PkgName); ok {
-				return fmt.Sprintf("%v.%v", pkg.Imported().Path(), target.Sel.Name), target.Sel.Name, true
+				return fmt.Sprintf("%v.%v", pkg.Imported().Path(), target.Sel.Name), pkg.Imported().Path(), target.Sel.Name, true
 			}
 		}
 		// Method calls
 		if typ, ok := v.pkg.TypesInfo.Types[target.X]; ok {
-			return fmt.Sprintf("%v.%v", typ.Type.String(), target.Sel.Name), target.Sel.Name, true
+			if def, ok := v.pkg.TypesInfo.Uses[target.Sel]; ok && def.Pkg() != nil {
+				pkgPath = def.Pkg().Path()
+			}
+			recv := typ.Type
+			// methods and fields that are promoted from an embedded field are keyed by the type that declares them
+			if sel, ok := v.pkg.TypesInfo.Selections[target]; ok && len(sel.Index()) > 1 {
+				recv = declaringType(sel)
+			}
+			return fmt.Sprintf("%v.%v", typeKey(unalias(recv)), target.Sel.Name), pkgPath, target.Sel.Name, true
 		}
 	}
-	return "", "", false
+	return "", "", "", false
+}
+
+// declaringType returns the type that declares the method or field of the provided selection, which differs from the
+// type of the receiver of the selection if the method or field is promoted from an embedded field.
+func declaringType(sel *types.Selection) types.Type {
+	if sel.Kind() != types.FieldVal {
+		return sel.Obj().Type().(*types.Signature).Recv().Type()
+	}
+	typ := sel.Recv()
+	for _, idx := range sel.Index()[:len(sel.Index())-1] {
+		if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		typ = typ.Underlying().(*types.Struct).Field(idx).Type()
+	}
+	return typ
+}
+
+// unalias returns the provided type with type aliases resolved to the types that they denote, including the alias of
+// the element type of a pointer, so that the methods called on an alias have the same key as the methods called on the
+// aliased type.
+func unalias(typ types.Type) types.Type {
+	if ptr, ok := types.Unalias(typ).(*types.Pointer); ok {
+		return types.NewPointer(types.Unalias(ptr.Elem()))
+	}
+	return types.Unalias(typ)
+}
+
+// typeKey returns the string that represents the provided receiver type in function keys, which is the string
+// representation of the type with the type arguments or type parameters of a generic type removed, so that the methods
+// of every instantiation of "example.com/codec.Codec[T]", such as "example.com/codec.Codec[string]", are keyed as
+// methods of "example.com/codec.Codec" and are matched by the same rules.
+func typeKey(typ types.Type) string {
+	named, ok := typ.(*types.Named)
+	if ptr, isPtr := typ.(*types.Pointer); isPtr {
+		named, ok = types.Unalias(ptr.Elem()).(*types.Named)
+	}
+	if !ok || named.Origin().TypeParams().Len() == 0 {
+		return typ.String()
+	}
+	return stripTypeParams(typ.String())
+}
+
+func (v *visitor) errorAt(pos token.Pos, method, rule string, argument int, severity Severity, problem string) {
+	v.report(pos, OutParamError{
+		Method:   method,
+		Rule:     rule,
+		Argument: argument,
+		Severity: severity,
+		Problem:  problem,
+	})
 }
 
-func (v *visitor) errorAt(pos token.Pos, method string, argument int) {
+// report reports the provided error at the provided position unless the error is suppressed. The position and the
+// source line of the error are set from the position.
+func (v *visitor) report(pos token.Pos, err OutParamError) {
 	position := v.pkg.Fset.Position(pos)
+	if v.skipFiles.matches(position.Filename) || v.directives(position.Filename).suppresses(position) {
+		return
+	}
 	lines, ok := v.lines[position.Filename]
 	if !ok {
-		contents, err := ioutil.ReadFile(position.Filename)
-		if err != nil {
-			contents = nil
+		contents, ok := v.overlay[position.Filename]
+		if !ok {
+			var readErr error
+			if contents, readErr = ioutil.ReadFile(position.Filename); readErr != nil {
+				contents = nil
+			}
 		}
 		lines = strings.Split(string(contents), "\n")
 		v.lines[position.Filename] = lines
@@ -223,32 +1037,105 @@ func (v *visitor) errorAt(pos token.Pos, method string, argument int) {
 	if position.Line-1 < len(lines) {
 		line = strings.TrimSpace(lines[position.Line-1])
 	}
-	v.errors = append(v.errors, OutParamError{position, line, method, argument})
+	err.Pos = position
+	err.Line = line
+	v.errors = append(v.errors, err)
 }
 
-func isAddr(expr ast.Expr) bool {
+// isArgAddr returns true if the argument with the provided index of the provided call is an address.
+func (v *visitor) isArgAddr(call *ast.CallExpr, i int) bool {
+	if v.ssaCalls != nil {
+		if value, ok := v.ssaCalls.ssaArg(call, i); ok {
+			return isSSAAddr(value, make(map[*ssa.Phi]bool))
+		}
+	}
+	return v.isAddr(call.Args[i])
+}
+
+func (v *visitor) isAddr(expr ast.Expr) bool {
 	switch expr := expr.(type) {
 	case *ast.UnaryExpr:
 		// The expected usage for output parameters, which is &x
 		return expr.Op == token.AND
+	case *ast.CallExpr:
+		// a conversion to a pointer or interface type, such as (*T)(p) or unsafe.Pointer(p), is an address if the
+		// converted value is
+		if tv, ok := v.pkg.TypesInfo.Types[expr.Fun]; ok && tv.IsType() && len(expr.Args) == 1 {
+			if !isPointer(tv.Type) && !types.IsInterface(tv.Type) {
+				return false
+			}
+			return v.isAddr(expr.Args[0]) || isPointer(v.pkg.TypesInfo.TypeOf(expr.Args[0]))
+		}
+		// new(T) returns the address of a new value
+		fun, ok := ast.Unparen(expr.Fun).(*ast.Ident)
+		if !ok {
+			return false
+		}
+		builtin, ok := v.pkg.TypesInfo.Uses[fun].(*types.Builtin)
+		return ok && builtin.Name() == "new"
 	case *ast.StarExpr:
 		// Allow *&x as an explicit way to signal that no & is intended
-		child, ok := expr.X.(*ast.UnaryExpr)
-		return ok && child.Op == token.AND
+		return isDerefAddr(expr)
 	case *ast.Ident:
-		if expr.Obj != nil && expr.Obj.Decl != nil {
-			switch child := expr.Obj.Decl.(type) {
-			case *ast.AssignStmt:
-				return isAddr(child.Rhs[0])
+		switch obj := v.pkg.TypesInfo.Uses[expr].(type) {
+		case *types.Var:
+			values, ok := v.reachingValues(obj, expr.Pos())
+			if !ok {
+				return false
+			}
+			// a declaration without a value that is followed by conditional assignments is assumed to be overwritten
+			if len(values) > 1 && values[0].rhs == nil {
+				values = values[1:]
 			}
+			for _, value := range values {
+				if !v.isAssignedAddr(value.rhs, value.index) {
+					return false
+				}
+			}
+			return true
+		case *types.Nil:
+			// Allow passing literal nil
+			return true
 		}
-		// Allow passing a pointer or literal nil
-		return expr.Name == "nil"
+		return false
 	default:
 		return false
 	}
 }
 
+// isAssignedAddr returns true if the value with the provided index that is assigned by the provided right-hand side of
+// an assignment or declaration is an address. The values whose type is a pointer, such as the result of a constructor,
+// are addresses even if they are assigned to variables of an interface type. The right-hand side is either a value for
+// every variable or a single call or other expression that returns multiple values.
+func (v *visitor) isAssignedAddr(rhs []ast.Expr, i int) bool {
+	if i < len(rhs) && v.isAddr(rhs[i]) {
+		return true
+	}
+	typ := v.assignedType(rhs, i)
+	return typ != nil && isPointer(typ)
+}
+
+// assignedType returns the type of the value with the provided index of the provided right-hand side of an assignment,
+// which may be a single call that returns multiple values, or nil if the right-hand side has no such value.
+func (v *visitor) assignedType(rhs []ast.Expr, i int) types.Type {
+	switch {
+	case len(rhs) == 1 && i > 0:
+		tuple, ok := v.pkg.TypesInfo.TypeOf(rhs[0]).(*types.Tuple)
+		if !ok || i >= tuple.Len() {
+			return nil
+		}
+		return tuple.At(i).Type()
+	case i < len(rhs):
+		typ := v.pkg.TypesInfo.TypeOf(rhs[i])
+		if tuple, ok := typ.(*types.Tuple); ok && tuple.Len() > 0 {
+			typ = tuple.At(0).Type()
+		}
+		return typ
+	default:
+		return nil
+	}
+}
+
 func reportErrors(errs []OutParamError) {
 	sort.Sort(byLocation(errs))
 	for _, err := range errs {
@@ -256,6 +1143,17 @@ func reportErrors(errs []OutParamError) {
 	}
 }
 
+// countSeverity returns the number of the provided errors that have the provided severity.
+func countSeverity(errs []OutParamError, severity Severity) int {
+	count := 0
+	for _, err := range errs {
+		if err.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
 func plural(count int, singular, plural string) string {
 	if count == 1 {
 		return fmt.Sprintf("%d %s", count, singular)
@@ -9,6 +9,8 @@ import (
 	"go/token"
 	"io/ioutil"
 	"path"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/nmiyake/pkg/dirs"
@@ -51,6 +53,7 @@ func TestOutParamCheck(t *testing.T) {
 					},
 					Line:     `json.Unmarshal(j, x)`,
 					Method:   "Unmarshal",
+					Rule:     "encoding/json.Unmarshal",
 					Argument: 1,
 				},
 			},
@@ -80,6 +83,7 @@ func TestOutParamCheck(t *testing.T) {
 					},
 					Line:     `_ = json.Unmarshal(j, x)`,
 					Method:   "Unmarshal",
+					Rule:     "encoding/json.Unmarshal",
 					Argument: 1,
 				},
 			},
@@ -109,6 +113,7 @@ func TestOutParamCheck(t *testing.T) {
 					},
 					Line:     `go json.Unmarshal(j, x)`,
 					Method:   "Unmarshal",
+					Rule:     "encoding/json.Unmarshal",
 					Argument: 1,
 				},
 			},
@@ -138,6 +143,7 @@ func TestOutParamCheck(t *testing.T) {
 					},
 					Line:     `defer json.Unmarshal(j, x)`,
 					Method:   "Unmarshal",
+					Rule:     "encoding/json.Unmarshal",
 					Argument: 1,
 				},
 			},
@@ -168,6 +174,7 @@ func TestOutParamCheck(t *testing.T) {
 					},
 					Line:     `c <- json.Unmarshal(j, x)`,
 					Method:   "Unmarshal",
+					Rule:     "encoding/json.Unmarshal",
 					Argument: 1,
 				},
 			},
@@ -197,6 +204,7 @@ func TestOutParamCheck(t *testing.T) {
 					},
 					Line:     `return json.Unmarshal(j, x)`,
 					Method:   "Unmarshal",
+					Rule:     "encoding/json.Unmarshal",
 					Argument: 1,
 				},
 			},
@@ -229,6 +237,7 @@ func TestOutParamCheck(t *testing.T) {
 					},
 					Line:     `case json.Unmarshal(j, x) == nil:`,
 					Method:   "Unmarshal",
+					Rule:     "encoding/json.Unmarshal",
 					Argument: 1,
 				},
 			},
@@ -264,6 +273,7 @@ func TestOutParamCheck(t *testing.T) {
 					},
 					Line:     `err: json.Unmarshal(j, x),`,
 					Method:   "Unmarshal",
+					Rule:     "encoding/json.Unmarshal",
 					Argument: 1,
 				},
 			},
@@ -301,6 +311,7 @@ func TestOutParamCheck(t *testing.T) {
 					},
 					Line:     `json.Unmarshal(j, x)`,
 					Method:   "Unmarshal",
+					Rule:     "encoding/json.Unmarshal",
 					Argument: 1,
 				},
 			},
@@ -333,19 +344,7 @@ func TestOutParamCheck(t *testing.T) {
 	defer cleanup()
 
 	for _, tc := range tcs {
-		// write program to temp file
-		currCaseDir, err := ioutil.TempDir(tmpDir, "")
-		require.NoError(t, err)
-
-		fpath := path.Join(currCaseDir, "main.go")
-		err = ioutil.WriteFile(fpath, []byte(tc.input), 0644)
-		require.NoError(t, err)
-
-		// load package for program
-		pkgs, err := packages.Load(&packages.Config{
-			Mode: packages.LoadSyntax,
-		}, "./"+currCaseDir)
-		require.NoError(t, err)
+		pkgs := loadTestPackage(t, tmpDir, tc.input)
 
 		// update the expected outparam output filename
 		for i := range tc.expected {
@@ -356,6 +355,2060 @@ func TestOutParamCheck(t *testing.T) {
 		errs := run(pkgs, defaultCfg)
 
 		// assert expectations
-		assert.Equal(t, tc.expected, errs)
+		assert.Equal(t, tc.expected, errs, tc.name)
+	}
+}
+
+func TestOutParamCheckRules(t *testing.T) {
+	allowNilCfg, err := loadCfg(`{
+		"version": 2,
+		"rules": {
+			"encoding/json.Unmarshal": {"args": [1], "allowNil": false},
+			"encoding/xml.Unmarshal": {"args": [1], "allowNil": true}
+		}
+	}`)
+	require.NoError(t, err)
+	matchModesInput := `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		func main() {
+			j := []byte("...")
+			var x interface{}
+			json.Unmarshal(j, x)
+			json.NewDecoder(nil).Decode(x)
+		}
+		`
+	missingArgsInput := `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		func main() {
+			var x interface{}
+			json.Unmarshal([]byte("..."), &x)
+		}
+		`
+	exclusionsInput := `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		type codec struct{}
+
+		func (codec) Unmarshal(data []byte, v interface{}) error { return nil }
+
+		func main() {
+			var x interface{}
+			json.Unmarshal([]byte("{}"), x)
+			codec{}.Unmarshal([]byte("{}"), x)
+		}
+		`
+
+	runOutParamTestCases(t, []outParamTestCase{
+		{
+			name: "severity",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			func main() {
+				j := []byte("...")
+				var x interface{}
+				json.Unmarshal(j, x)
+			}
+		`,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"encoding/json.Unmarshal": {Args: indexArgs(1), Severity: SeverityWarning},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 140, Line: 11, Column: 23}, Line: "json.Unmarshal(j, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Severity: SeverityWarning},
+			},
+		},
+		{
+			name: "allow nil",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"encoding/xml"
+			)
+
+			func main() {
+				j := []byte("...")
+				var x interface{}
+				json.Unmarshal(j, nil)
+				json.Unmarshal(j, (nil))
+				json.Unmarshal(j, &x)
+				xml.Unmarshal(j, nil)
+			}
+		`,
+			cfg: allowNilCfg,
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 159, Line: 12, Column: 23}, Line: "json.Unmarshal(j, nil)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "must not be nil"},
+				{Pos: token.Position{Offset: 186, Line: 13, Column: 23}, Line: "json.Unmarshal(j, (nil))", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "must not be nil"},
+			},
+		},
+		{
+			name: "default rules",
+			input: `
+			package main
+
+			import (
+				"encoding/asn1"
+				"encoding/gob"
+				"encoding/xml"
+				"fmt"
+				"gopkg.in/yaml.v3"
+				"net/rpc"
+				"os"
+				"reflect"
+			)
+
+			func main() {
+				var client *rpc.Client
+				var reply string
+				var xmlDec *xml.Decoder
+				var doc struct{}
+				var gobDec *gob.Decoder
+				var msg struct{}
+				var cert struct{ Version int }
+				var count, total int
+				var yamlDec *yaml.Decoder
+				var node yaml.Node
+				client.Call("Service.Method", 1, reply)
+				client.Go("Service.Method", 1, reply, nil)
+				client.Call("Service.Method", 1, &reply)
+				xml.Unmarshal(nil, doc)
+				xmlDec.Decode(doc)
+				xmlDec.DecodeElement(doc, nil)
+				xmlDec.Decode(&doc)
+				gobDec.Decode(msg)
+				gobDec.DecodeValue(reflect.ValueOf(msg))
+				gobDec.DecodeValue(reflect.ValueOf(&msg))
+				gobDec.DecodeValue(reflect.ValueOf(&msg).Elem())
+				asn1.Unmarshal(nil, cert)
+				asn1.UnmarshalWithParams(nil, cert, "explicit")
+				asn1.Unmarshal(nil, &cert)
+				fmt.Sscan("1 2", &count, total)
+				fmt.Sscanf("1", "%d", count)
+				fmt.Fscanln(os.Stdin, &count, &total)
+				yaml.Unmarshal(nil, node)
+				yamlDec.Decode(node)
+				yamlDec.Decode(&node)
+			}
+		`,
+			cfg: defaultCfg,
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 477, Line: 26, Column: 38}, Line: `client.Call("Service.Method", 1, reply)`, Method: "Call", Rule: "net/rpc.Client.Call", Argument: 2},
+				{Pos: token.Position{Offset: 519, Line: 27, Column: 36}, Line: `client.Go("Service.Method", 1, reply, nil)`, Method: "Go", Rule: "net/rpc.Client.Go", Argument: 2},
+				{Pos: token.Position{Offset: 599, Line: 29, Column: 24}, Line: "xml.Unmarshal(nil, doc)", Method: "Unmarshal", Rule: "encoding/xml.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 622, Line: 30, Column: 19}, Line: "xmlDec.Decode(doc)", Method: "Decode", Rule: "encoding/xml.Decoder.Decode"},
+				{Pos: token.Position{Offset: 652, Line: 31, Column: 26}, Line: "xmlDec.DecodeElement(doc, nil)", Method: "DecodeElement", Rule: "encoding/xml.Decoder.DecodeElement"},
+				{Pos: token.Position{Offset: 704, Line: 33, Column: 19}, Line: "gobDec.Decode(msg)", Method: "Decode", Rule: "encoding/gob.Decoder.Decode"},
+				{Pos: token.Position{Offset: 732, Line: 34, Column: 24}, Line: "gobDec.DecodeValue(reflect.ValueOf(msg))", Method: "DecodeValue", Rule: "encoding/gob.Decoder.DecodeValue", Problem: "holds a copy of the value that cannot be decoded into; pass the reflect.Value of its address instead"},
+				{Pos: token.Position{Offset: 877, Line: 37, Column: 25}, Line: "asn1.Unmarshal(nil, cert)", Method: "Unmarshal", Rule: "encoding/asn1.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 917, Line: 38, Column: 35}, Line: `asn1.UnmarshalWithParams(nil, cert, "explicit")`, Method: "UnmarshalWithParams", Rule: "encoding/asn1.UnmarshalWithParams", Argument: 1},
+				{Pos: token.Position{Offset: 995, Line: 40, Column: 30}, Line: `fmt.Sscan("1 2", &count, total)`, Method: "Sscan", Rule: "fmt.Sscan", Argument: 2},
+				{Pos: token.Position{Offset: 1028, Line: 41, Column: 27}, Line: `fmt.Sscanf("1", "%d", count)`, Method: "Sscanf", Rule: "fmt.Sscanf", Argument: 2},
+				{Pos: token.Position{Offset: 1101, Line: 43, Column: 25}, Line: "yaml.Unmarshal(nil, node)", Method: "Unmarshal", Rule: "gopkg.in/yaml.v3.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 1126, Line: 44, Column: 20}, Line: "yamlDec.Decode(node)", Method: "Decode", Rule: "gopkg.in/yaml.v3.Decoder.Decode"},
+			},
+		},
+		{
+			name:  "match suffix",
+			input: matchModesInput,
+			cfg:   argsConfig(map[string][]int{"json.Unmarshal": {1}, "json.Decoder.Decode": {0}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 132, Line: 11, Column: 22}, Line: "json.Unmarshal(j, x)", Method: "Unmarshal", Rule: "json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 166, Line: 12, Column: 32}, Line: "json.NewDecoder(nil).Decode(x)", Method: "Decode", Rule: "json.Decoder.Decode"},
+			},
+		},
+		{
+			name:  "match exact",
+			input: matchModesInput,
+			cfg: argsConfig(map[string][]int{
+				"encoding/json.Unmarshal":      {1},
+				"encoding/json.Decoder.Decode": {0},
+			}).Merge(Config{Match: MatchExact}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 132, Line: 11, Column: 22}, Line: "json.Unmarshal(j, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 166, Line: 12, Column: 32}, Line: "json.NewDecoder(nil).Decode(x)", Method: "Decode", Rule: "encoding/json.Decoder.Decode"},
+			},
+		},
+		{
+			name:  "match exact with partial names",
+			input: matchModesInput,
+			cfg: argsConfig(map[string][]int{
+				"json.Unmarshal":      {1},
+				"json.Decoder.Decode": {0},
+			}).Merge(Config{Match: MatchExact}),
+		},
+		{
+			name:  "match exact for rule",
+			input: matchModesInput,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"json.Unmarshal":      {Args: indexArgs(1), Match: MatchExact},
+					"json.Decoder.Decode": {Args: indexArgs(0)},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 166, Line: 12, Column: 32}, Line: "json.NewDecoder(nil).Decode(x)", Method: "Decode", Rule: "json.Decoder.Decode"},
+			},
+		},
+		{
+			name:  "match suffix for rule",
+			input: matchModesInput,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"json.Unmarshal": {Args: indexArgs(1), Match: MatchSuffix},
+				},
+				Match: MatchExact,
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 132, Line: 11, Column: 22}, Line: "json.Unmarshal(j, x)", Method: "Unmarshal", Rule: "json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name:  "match regex",
+			input: matchModesInput,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					`.*\.(Unmarshal|Decode)`: {Args: indexArgs(0), Match: MatchRegex, Packages: []string{"encoding/json"}},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 129, Line: 11, Column: 19}, Line: "json.Unmarshal(j, x)", Method: "Unmarshal", Rule: ".*\\.(Unmarshal|Decode)"},
+				{Pos: token.Position{Offset: 166, Line: 12, Column: 32}, Line: "json.NewDecoder(nil).Decode(x)", Method: "Decode", Rule: ".*\\.(Unmarshal|Decode)"},
+			},
+		},
+		{
+			name:  "match regex in other package",
+			input: matchModesInput,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					`.*\.(Unmarshal|Decode)`: {Args: indexArgs(0), Match: MatchRegex, Packages: []string{"encoding/xml"}},
+				},
+			},
+		},
+		{
+			name:  "match glob",
+			input: matchModesInput,
+			cfg:   argsConfig(map[string][]int{"encoding/json.*": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 132, Line: 11, Column: 22}, Line: "json.Unmarshal(j, x)", Method: "Unmarshal", Rule: "encoding/json.*", Argument: 1},
+			},
+		},
+		{
+			name: "variadic args",
+			input: `
+			package main
+
+			import (
+				"fmt"
+			)
+
+			func main() {
+				var a, b, c int
+				fmt.Sscan("1 2 3", &a, b, c)
+			}
+		`,
+			cfg: Config{Rules: map[string]*Rule{"fmt.Sscan": {Args: []Arg{{Index: 1, Variadic: true}}}}},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 110, Line: 10, Column: 28}, Line: `fmt.Sscan("1 2 3", &a, b, c)`, Method: "Sscan", Rule: "fmt.Sscan", Argument: 2},
+				{Pos: token.Position{Offset: 113, Line: 10, Column: 31}, Line: `fmt.Sscan("1 2 3", &a, b, c)`, Method: "Sscan", Rule: "fmt.Sscan", Argument: 3},
+			},
+		},
+		{
+			name: "empty interface args",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"fmt"
+			)
+
+			func main() {
+				var a, b int
+				fmt.Sscan("1 2", &a, b)
+				json.Unmarshal([]byte("1"), a)
+			}
+		`,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"encoding/json.Unmarshal": {Args: []Arg{{EmptyInterfaces: true}}},
+					"fmt.Sscan":               {Args: []Arg{{EmptyInterfaces: true}}},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 125, Line: 11, Column: 26}, Line: `fmt.Sscan("1 2", &a, b)`, Method: "Sscan", Rule: "fmt.Sscan", Argument: 2},
+				{Pos: token.Position{Offset: 160, Line: 12, Column: 33}, Line: `json.Unmarshal([]byte("1"), a)`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "spread args",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"fmt"
+			)
+
+			func read() ([]byte, interface{}) {
+				return nil, nil
+			}
+
+			func main() {
+				var a, b int
+				args := []interface{}{&a, &b}
+				fmt.Sscan("1 2", args...)
+				json.Unmarshal(read())
+			}
+		`,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"encoding/json.Unmarshal": {Args: indexArgs(1)},
+					"fmt.Sscan":               {Args: []Arg{{Index: 1, Variadic: true}, {Index: 3}}},
+				},
+			},
+		},
+		{
+			name:  "existing arg",
+			input: missingArgsInput,
+			cfg:   argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+		},
+		{
+			name:  "missing arg",
+			input: missingArgsInput,
+			cfg:   argsConfig(map[string][]int{"encoding/json.Unmarshal": {1, 2}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 92, Line: 10, Column: 4}, Line: `json.Unmarshal([]byte("..."), &x)`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 2, Problem: `does not exist since the function has 2 parameters; the rule "encoding/json.Unmarshal" may be out of date`},
+			},
+		},
+		{
+			name:  "missing negative arg",
+			input: missingArgsInput,
+			cfg:   argsConfig(map[string][]int{"encoding/json.Unmarshal": {-3}}),
+		},
+		{
+			name:  "missing arg of glob rule",
+			input: missingArgsInput,
+			cfg:   argsConfig(map[string][]int{"encoding/json.*": {2}}),
+		},
+		{
+			name:  "missing arg of regex rule",
+			input: missingArgsInput,
+			cfg:   Config{Rules: map[string]*Rule{`encoding/json\.Unmarshal`: {Args: indexArgs(2), Match: MatchRegex}}},
+		},
+		{
+			name: "arg kinds",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type config struct{}
+
+			func main() {
+				var c config
+				var n int
+				var x interface{}
+				json.Unmarshal([]byte("{}"), &c)
+				json.Unmarshal([]byte("{}"), &n)
+				json.Unmarshal([]byte("{}"), x)
+				json.Unmarshal([]byte("{}"), &x)
+			}
+		`,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"encoding/json.Unmarshal": {Args: []Arg{{Index: 1, Kind: ArgKindPtrToStruct}}},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 221, Line: 15, Column: 34}, Line: `json.Unmarshal([]byte("{}"), &n)`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "must be a pointer to a struct, was *int"},
+				{Pos: token.Position{Offset: 258, Line: 16, Column: 34}, Line: `json.Unmarshal([]byte("{}"), x)`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "negative args",
+			input: `
+			package main
+
+			func Load(opts []string, out interface{}) {}
+
+			func main() {
+				var x interface{}
+				Load(nil, x)
+			}
+		`,
+			cfg: Config{Rules: map[string]*Rule{".Load": {Args: indexArgs(-1)}}},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 120, Line: 8, Column: 15}, Line: "Load(nil, x)", Method: "Load", Rule: ".Load", Argument: 1},
+			},
+		},
+		{
+			name: "unmarshal-like packages",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"strings"
+			)
+
+			func main() {
+				var x interface{}
+				json.Unmarshal([]byte("{}"), x)
+				dec := json.NewDecoder(strings.NewReader("{}"))
+				dec.Decode(x)
+				dec.Decode(&x)
+			}
+		`,
+			cfg: Config{Rules: map[string]*Rule{"encoding/json": {UnmarshalLike: true}}},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 142, Line: 11, Column: 34}, Line: `json.Unmarshal([]byte("{}"), x)`, Method: "Unmarshal", Rule: "encoding/json", Argument: 1},
+				{Pos: token.Position{Offset: 212, Line: 13, Column: 16}, Line: "dec.Decode(x)", Method: "Decode", Rule: "encoding/json"},
+			},
+		},
+		{
+			name:  "function name",
+			input: exclusionsInput,
+			cfg:   argsConfig(map[string][]int{"Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 219, Line: 14, Column: 33}, Line: `json.Unmarshal([]byte("{}"), x)`, Method: "Unmarshal", Rule: "Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 257, Line: 15, Column: 36}, Line: `codec{}.Unmarshal([]byte("{}"), x)`, Method: "Unmarshal", Rule: "Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name:  "excluded function",
+			input: exclusionsInput,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"Unmarshal":                {Args: indexArgs(1)},
+					"!encoding/json.Unmarshal": {Exclude: true},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 257, Line: 15, Column: 36}, Line: `codec{}.Unmarshal([]byte("{}"), x)`, Method: "Unmarshal", Rule: "Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name:  "excluded glob",
+			input: exclusionsInput,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"Unmarshal":                {Args: indexArgs(1)},
+					"!encoding/json.Unmarshal": {Exclude: true},
+					"!*.Unmarshal":             {Exclude: true},
+				},
+			},
+		},
+	})
+}
+
+func TestOutParamCheckErrorsAs(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"errors"
+			"os"
+		)
+
+		type timeout interface {
+			Timeout() bool
+		}
+
+		type notError struct{}
+
+		func main() {
+			var err error
+			var pathErr *os.PathError
+			var t timeout
+			var target interface{}
+			var value os.PathError
+			var other notError
+			errors.As(err, &pathErr)
+			errors.As(err, &t)
+			errors.As(err, target)
+			errors.As(err, nil)
+			errors.As(err, pathErr)
+			errors.As(err, &value)
+			errors.As(err, &other)
+		}
+		`)
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 350, Line: 24, Column: 19}, Line: "errors.As(err, target)", Method: "As", Rule: "errors.As", Argument: 1},
+		{Pos: token.Position{Offset: 376, Line: 25, Column: 19}, Line: "errors.As(err, nil)", Method: "As", Rule: "errors.As", Argument: 1, Problem: "must not be nil"},
+		{Pos: token.Position{Offset: 399, Line: 26, Column: 19}, Line: "errors.As(err, pathErr)", Method: "As", Rule: "errors.As", Argument: 1, Problem: "is a pointer to os.PathError, which does not implement error; *os.PathError does, so pass the address of a variable of type *os.PathError"},
+		{Pos: token.Position{Offset: 426, Line: 27, Column: 19}, Line: "errors.As(err, &value)", Method: "As", Rule: "errors.As", Argument: 1, Problem: "is a pointer to os.PathError, which does not implement error; *os.PathError does, so pass the address of a variable of type *os.PathError"},
+		{Pos: token.Position{Offset: 452, Line: 28, Column: 19}, Line: "errors.As(err, &other)", Method: "As", Rule: "errors.As", Argument: 1, Problem: "is a pointer to " + pkgs[0].PkgPath + ".notError, which does not implement error; errors.As panics unless the target points to an interface or to a type that implements error"},
+	}, run(pkgs, defaultCfg))
+}
+
+func TestOutParamCheckStrict(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		func main() {
+			j := []byte("...")
+			var x interface{}
+			json.Unmarshal(j, nil)
+			json.Unmarshal(j, *&x)
+			json.Unmarshal(j, &x)
+		}
+		`)
+	cfg := argsConfig(map[string][]int{
+		"encoding/json.Unmarshal": {1},
+	})
+	pkgCfg := func(*packages.Package) Config {
+		return cfg
+	}
+	assert.Empty(t, runWithConfigs(pkgs, pkgCfg, nil, analysis{}))
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 132, Line: 11, Column: 22}, Line: "json.Unmarshal(j, nil)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "must not be nil"},
+		{Pos: token.Position{Offset: 158, Line: 12, Column: 22}, Line: "json.Unmarshal(j, *&x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "passes a copy using *&; suppress the error with a directive if a copy is intended"},
+	}, runWithConfigs(pkgs, pkgCfg, nil, analysis{strict: true}))
+}
+
+func TestOutParamCheckModuleRelativeNames(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		func Load(path string, out interface{}) {}
+
+		func main() {
+			var x interface{}
+			Load("config.yml", x)
+		}
+		`)
+	require.NotNil(t, pkgs[0].Module)
+	relPath := strings.TrimPrefix(pkgs[0].PkgPath, pkgs[0].Module.Path+"/")
+	cfg := argsConfig(map[string][]int{
+		"./" + relPath + ".Load": {1},
+	})
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 122, Line: 8, Column: 23}, Line: `Load("config.yml", x)`, Method: "Load", Rule: pkgs[0].PkgPath + ".Load", Argument: 1},
+	}, run(pkgs, cfg))
+
+	cfg = argsConfig(map[string][]int{
+		"./other.Load": {1},
+	})
+	assert.Empty(t, run(pkgs, cfg))
+}
+
+func TestOutParamCheckSignatures(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		func ReadConfig(path string, out interface{}) error { return nil }
+		func LoadSettings(out interface{}) error { return nil }
+
+		func main() {
+			var x interface{}
+			ReadConfig("config.yml", x)
+			LoadSettings(&x)
+			LoadSettings(x)
+		}
+		`)
+	cfg := Config{
+		Rules: map[string]*Rule{
+			"ReadConfig": {Args: indexArgs(1), Severity: SeverityWarning},
+		},
+		Signatures: []*SignatureRule{
+			{Packages: []string{pkgs[0].PkgPath}, Params: []string{"...", "&interface{}"}, Results: []string{"error"}},
+		},
+	}
+	// the signature rules take precedence over the severity of the matching rule
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 210, Line: 9, Column: 29}, Line: `ReadConfig("config.yml", x)`, Method: "ReadConfig", Rule: "ReadConfig", Argument: 1},
+		{Pos: token.Position{Offset: 249, Line: 11, Column: 17}, Line: "LoadSettings(x)", Method: "LoadSettings"},
+	}, run(pkgs, cfg))
+}
+
+func TestOutParamCheckCallTargets(t *testing.T) {
+	runOutParamTestCases(t, []outParamTestCase{
+		{
+			name: "interface methods",
+			input: `
+			package main
+
+			type Decoder interface {
+				DecodeInto(out interface{}) error
+			}
+
+			type jsonCodec struct{}
+
+			func (d *jsonCodec) DecodeInto(out interface{}) error { return nil }
+
+			type other struct{}
+
+			func (o other) DecodeInto(out interface{}, strict bool) error { return nil }
+
+			func main() {
+				var x interface{}
+				var d Decoder = &jsonCodec{}
+				d.DecodeInto(x)
+				var jd jsonCodec
+				jd.DecodeInto(x)
+				(&jsonCodec{}).DecodeInto(x)
+				other{}.DecodeInto(x, true)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"Decoder.DecodeInto": {0}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 385, Line: 19, Column: 18}, Line: "d.DecodeInto(x)", Method: "DecodeInto", Rule: "Decoder.DecodeInto"},
+				{Pos: token.Position{Offset: 427, Line: 21, Column: 19}, Line: "jd.DecodeInto(x)", Method: "DecodeInto", Rule: "Decoder.DecodeInto"},
+				{Pos: token.Position{Offset: 460, Line: 22, Column: 31}, Line: "(&jsonCodec{}).DecodeInto(x)", Method: "DecodeInto", Rule: "Decoder.DecodeInto"},
+			},
+		},
+		{
+			name: "generic functions",
+			input: `
+			package main
+
+			type Config struct{}
+
+			func Unmarshal[T any](data []byte, out T) error { return nil }
+
+			func Convert[A, B any](in A, out B) {}
+
+			func main() {
+				var c Config
+				Unmarshal[Config](nil, c)
+				Unmarshal(nil, c)
+				Unmarshal[*Config](nil, &c)
+				Convert[int, Config](1, c)
+				funcs := []func(out interface{}){}
+				funcs[0](c)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{".Unmarshal[T]": {1}, ".Convert[A, B]": {1}, ".funcs": {0}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 214, Line: 12, Column: 28}, Line: "Unmarshal[Config](nil, c)", Method: "Unmarshal", Rule: ".Unmarshal[T]", Argument: 1},
+				{Pos: token.Position{Offset: 236, Line: 13, Column: 20}, Line: "Unmarshal(nil, c)", Method: "Unmarshal", Rule: ".Unmarshal[T]", Argument: 1},
+				{Pos: token.Position{Offset: 299, Line: 15, Column: 29}, Line: "Convert[int, Config](1, c)", Method: "Convert", Rule: ".Convert[A, B]", Argument: 1},
+			},
+		},
+		{
+			name: "generic wrappers",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type Config struct{}
+
+			type pointer[T any] interface {
+				*T
+			}
+
+			func Decode[T any](b []byte, dst *T) error { return json.Unmarshal(b, dst) }
+
+			func DecodeNew[T any, PT interface{ *T }](b []byte) (PT, error) {
+				v := PT(new(T))
+				return v, json.Unmarshal(b, v)
+			}
+
+			func DecodeInto[PT pointer[T], T any](b []byte, v PT) error { return json.Unmarshal(b, v) }
+
+			func DecodeAny[T any](b []byte, v T) error { return json.Unmarshal(b, v) }
+
+			func DecodeValue[T any](b []byte) (T, error) {
+				var v T
+				return v, json.Unmarshal(b, v)
+			}
+
+			func DecodeAll[S ~[]*T, T any](b []byte, s S) error { return json.Unmarshal(b, s[0]) }
+
+			func Store[T any](dst T) {}
+
+			func main() {
+				var c Config
+				Decode[Config](nil, &c)
+				Decode(nil, &c)
+				DecodeInto(nil, &c)
+				Store[Config](c)
+				Store[*Config](&c)
+			}
+		`,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"encoding/json.Unmarshal": {Args: indexArgs(1)},
+					".Store[T]":               {Args: indexArgs(0)},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 509, Line: 23, Column: 74}, Line: "func DecodeAny[T any](b []byte, v T) error { return json.Unmarshal(b, v) }", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 609, Line: 27, Column: 33}, Line: "return v, json.Unmarshal(b, v)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 865, Line: 39, Column: 19}, Line: "Store[Config](c)", Method: "Store", Rule: ".Store[T]"},
+			},
+		},
+		{
+			name: "generic methods",
+			input: `
+			package main
+
+			type Codec[T any] struct{}
+
+			func (c *Codec[T]) Decode(data []byte, v interface{}) error { return nil }
+
+			type Pair[K comparable, V any] struct{}
+
+			func (p Pair[K, V]) Decode(data []byte, v interface{}) error { return nil }
+
+			func (c *Codec[T]) decodeAll(data []byte, v interface{}) error { return c.Decode(data, v) }
+
+			func main() {
+				var x map[string]string
+				var c Codec[string]
+				c.Decode(nil, x)
+				(&Codec[map[string][]int]{}).Decode(nil, x)
+				Pair[string, []string]{}.Decode(nil, x)
+				c.Decode(nil, &x)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{".Codec.Decode": {1}, ".Pair[K,V].Decode": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 342, Line: 12, Column: 91}, Line: "func (c *Codec[T]) decodeAll(data []byte, v interface{}) error { return c.Decode(data, v) }", Method: "Decode", Rule: ".Codec.Decode", Argument: 1},
+				{Pos: token.Position{Offset: 435, Line: 17, Column: 19}, Line: "c.Decode(nil, x)", Method: "Decode", Rule: ".Codec.Decode", Argument: 1},
+				{Pos: token.Position{Offset: 483, Line: 18, Column: 46}, Line: "(&Codec[map[string][]int]{}).Decode(nil, x)", Method: "Decode", Rule: ".Codec.Decode", Argument: 1},
+				{Pos: token.Position{Offset: 527, Line: 19, Column: 42}, Line: "Pair[string, []string]{}.Decode(nil, x)", Method: "Decode", Rule: ".Pair[K,V].Decode", Argument: 1},
+			},
+		},
+		{
+			name: "function bindings",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			var unmarshal = json.Unmarshal
+
+			func main() {
+				var x interface{}
+				data := []byte("{}")
+				u := json.Unmarshal
+				u(data, x)
+				u(data, &x)
+				w := u
+				w(data, x)
+				unmarshal(data, x)
+
+				r := json.Unmarshal
+				r = func([]byte, interface{}) error { return nil }
+				r(data, x)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 191, Line: 14, Column: 13}, Line: "u(data, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 233, Line: 17, Column: 13}, Line: "w(data, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 256, Line: 18, Column: 21}, Line: "unmarshal(data, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "method values",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"strings"
+			)
+
+			type Decoder interface {
+				Decode(v interface{}) error
+			}
+
+			func main() {
+				var x interface{}
+				dec := json.NewDecoder(strings.NewReader("{}"))
+				f := dec.Decode
+				f(x)
+				f(&x)
+				g := (*json.Decoder).Decode
+				g(dec, x)
+				var d Decoder = dec
+				h := d.Decode
+				h(x)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"*encoding/json.Decoder.Decode": {0}, ".Decoder.Decode": {0}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 253, Line: 17, Column: 7}, Line: "f(x)", Method: "Decode", Rule: "*encoding/json.Decoder.Decode"},
+				{Pos: token.Position{Offset: 309, Line: 20, Column: 12}, Line: "g(dec, x)", Method: "Decode", Rule: "*encoding/json.Decoder.Decode", Argument: 1},
+				{Pos: token.Position{Offset: 360, Line: 23, Column: 7}, Line: "h(x)", Method: "Decode", Rule: ".Decoder.Decode"},
+			},
+		},
+		{
+			name: "variable initializers",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type config struct {
+				Err error
+			}
+
+			var target config
+
+			var errTarget = json.Unmarshal([]byte("{}"), target)
+
+			var (
+				errs = []error{json.Unmarshal([]byte("{}"), target)}
+				cfg  = config{Err: json.Unmarshal([]byte("{}"), target)}
+				ok   = json.Unmarshal([]byte("{}"), &target)
+			)
+
+			func main() {
+				var err = json.Unmarshal([]byte("{}"), target)
+				var _, _ = 0, json.Unmarshal([]byte("{}"), target)
+				_ = err
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 170, Line: 14, Column: 49}, Line: `var errTarget = json.Unmarshal([]byte("{}"), target)`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 236, Line: 17, Column: 49}, Line: `errs = []error{json.Unmarshal([]byte("{}"), target)}`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 297, Line: 18, Column: 53}, Line: `cfg  = config{Err: json.Unmarshal([]byte("{}"), target)}`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 421, Line: 23, Column: 44}, Line: `var err = json.Unmarshal([]byte("{}"), target)`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 476, Line: 24, Column: 48}, Line: `var _, _ = 0, json.Unmarshal([]byte("{}"), target)`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "nested calls",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"log"
+			)
+
+			func main() {
+				var x interface{}
+				data := []byte("{}")
+				log.Fatal(json.Unmarshal(data, x))
+				if err := json.Unmarshal(data, x); err != nil {
+				}
+				if json.Unmarshal(data, x) != nil {
+				}
+				for json.Unmarshal(data, x) != nil {
+				}
+				errs := make(chan error, 1)
+				select {
+				case errs <- json.Unmarshal(data, x):
+				case err := <-func() chan error { json.Unmarshal(data, x); return errs }():
+					_ = err
+				}
+				switch err := json.Unmarshal(data, x); {
+				case err != nil:
+				}
+				m := map[error]bool{json.Unmarshal(data, x): true}
+				_ = !(json.Unmarshal(data, x) == nil)
+				_ = []bool{json.Unmarshal(data, x) == nil}[0]
+				_, _ = m[json.Unmarshal(data, x)]
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 165, Line: 12, Column: 36}, Line: "log.Fatal(json.Unmarshal(data, x))", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 204, Line: 13, Column: 36}, Line: "if err := json.Unmarshal(data, x); err != nil {", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 255, Line: 15, Column: 29}, Line: "if json.Unmarshal(data, x) != nil {", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 302, Line: 17, Column: 30}, Line: "for json.Unmarshal(data, x) != nil {", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 403, Line: 21, Column: 39}, Line: "case errs <- json.Unmarshal(data, x):", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 466, Line: 22, Column: 60}, Line: "case err := <-func() chan error { json.Unmarshal(data, x); return errs }():", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 545, Line: 25, Column: 40}, Line: "switch err := json.Unmarshal(data, x); {", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 623, Line: 28, Column: 46}, Line: "m := map[error]bool{json.Unmarshal(data, x): true}", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 664, Line: 29, Column: 32}, Line: "_ = !(json.Unmarshal(data, x) == nil)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 711, Line: 30, Column: 37}, Line: "_ = []bool{json.Unmarshal(data, x) == nil}[0]", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 759, Line: 31, Column: 35}, Line: "_, _ = m[json.Unmarshal(data, x)]", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "function literals",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type handler struct {
+				decode func([]byte) error
+			}
+
+			var global = func(data []byte) {
+				var x interface{}
+				_ = json.Unmarshal(data, x)
+			}
+
+			func call(f func()) {
+				f()
+			}
+
+			func main() {
+				var x interface{}
+				h := handler{
+					decode: func(data []byte) error {
+						return json.Unmarshal(data, x)
+					},
+				}
+				h.decode = func(data []byte) error {
+					return json.Unmarshal(data, x)
+				}
+				call(func() {
+					_ = json.Unmarshal(nil, x)
+				})
+				handlers := map[string]func([]byte) error{
+					"json": func(data []byte) error {
+						return json.Unmarshal(data, x)
+					},
+				}
+				_ = []func(){func() {
+					_ = json.Unmarshal(nil, x)
+				}}
+				go func() {
+					_ = json.Unmarshal(nil, x)
+				}()
+				defer func() {
+					_ = json.Unmarshal(nil, x)
+				}()
+				func() {
+					func() {
+						_ = json.Unmarshal(nil, x)
+					}()
+				}()
+				_, _ = h, handlers
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 204, Line: 14, Column: 30}, Line: "_ = json.Unmarshal(data, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 382, Line: 25, Column: 35}, Line: "return json.Unmarshal(data, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 473, Line: 29, Column: 34}, Line: "return json.Unmarshal(data, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 529, Line: 32, Column: 30}, Line: "_ = json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 659, Line: 36, Column: 35}, Line: "return json.Unmarshal(data, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 731, Line: 40, Column: 30}, Line: "_ = json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 786, Line: 43, Column: 30}, Line: "_ = json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 845, Line: 46, Column: 30}, Line: "_ = json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 913, Line: 50, Column: 31}, Line: "_ = json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "wrapped call targets",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type unmarshalFunc func([]byte, interface{}) error
+
+			func Decode[T any](data []byte, v T) error {
+				return nil
+			}
+
+			func main() {
+				var x interface{}
+				_ = (json.Unmarshal)(nil, x)
+				_ = ((json.Unmarshal))(nil, x)
+				_ = unmarshalFunc(json.Unmarshal)(nil, x)
+				_ = (func([]byte, interface{}) error)(json.Unmarshal)(nil, x)
+				_ = (unmarshalFunc)((json.Unmarshal))(nil, x)
+				_ = (Decode[interface{}])(nil, x)
+				_ = (json.Unmarshal)(nil, &x)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}, ".Decode[T]": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 249, Line: 16, Column: 31}, Line: "_ = (json.Unmarshal)(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 284, Line: 17, Column: 33}, Line: "_ = ((json.Unmarshal))(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 330, Line: 18, Column: 44}, Line: "_ = unmarshalFunc(json.Unmarshal)(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 396, Line: 19, Column: 64}, Line: "_ = (func([]byte, interface{}) error)(json.Unmarshal)(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 446, Line: 20, Column: 48}, Line: "_ = (unmarshalFunc)((json.Unmarshal))(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 484, Line: 21, Column: 36}, Line: "_ = (Decode[interface{}])(nil, x)", Method: "Decode", Rule: ".Decode[T]", Argument: 1},
+			},
+		},
+		{
+			name: "shadowed packages",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				stdjson "encoding/json"
+				_ "encoding/xml"
+			)
+
+			type decoder struct{}
+
+			func (decoder) Unmarshal(data []byte, v interface{}) error { return nil }
+
+			type xmlDecoder struct{}
+
+			func (xmlDecoder) Unmarshal(data []byte, v interface{}) error { return nil }
+
+			func main() {
+				var x interface{}
+				_ = json.Unmarshal(nil, x)
+				_ = stdjson.Unmarshal(nil, x)
+				{
+					json := decoder{}
+					_ = json.Unmarshal(nil, x)
+				}
+				func(json decoder) {
+					_ = json.Unmarshal(nil, x)
+				}(decoder{})
+				xml := xmlDecoder{}
+				_ = xml.Unmarshal(nil, x)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}, "encoding/xml.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 386, Line: 20, Column: 29}, Line: "_ = json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 420, Line: 21, Column: 32}, Line: "_ = stdjson.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "type aliases",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"strings"
+			)
+
+			type Decoder = json.Decoder
+
+			func main() {
+				var x interface{}
+				var dec *Decoder = json.NewDecoder(strings.NewReader("{}"))
+				dec.Decode(x)
+				var val Decoder
+				val.Decode(x)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"json.Decoder.Decode": {0}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 220, Line: 14, Column: 16}, Line: "dec.Decode(x)", Method: "Decode", Rule: "json.Decoder.Decode"},
+				{Pos: token.Position{Offset: 258, Line: 16, Column: 16}, Line: "val.Decode(x)", Method: "Decode", Rule: "json.Decoder.Decode"},
+			},
+		},
+		{
+			name: "promoted methods",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"strings"
+			)
+
+			type wrapper struct {
+				*json.Decoder
+			}
+
+			type nested struct {
+				wrapper
+			}
+
+			func main() {
+				var x interface{}
+				w := wrapper{json.NewDecoder(strings.NewReader("{}"))}
+				w.Decode(x)
+				w.Decode(&x)
+				n := nested{w}
+				n.Decode(x)
+			}
+		`,
+			cfg: defaultCfg.Merge(Config{Match: MatchExact}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 272, Line: 20, Column: 14}, Line: "w.Decode(x)", Method: "Decode", Rule: "encoding/json.Decoder.Decode"},
+				{Pos: token.Position{Offset: 324, Line: 23, Column: 14}, Line: "n.Decode(x)", Method: "Decode", Rule: "encoding/json.Decoder.Decode"},
+			},
+		},
+		{
+			name: "dot imports",
+			input: `
+			package main
+
+			import (
+				. "encoding/json"
+				"strings"
+			)
+
+			func main() {
+				var x interface{}
+				Unmarshal([]byte("{}"), x)
+				dec := NewDecoder(strings.NewReader("{}"))
+				dec.Decode(x)
+				NewDecoder(strings.NewReader("{}")).Decode(x)
+				var val Decoder
+				val.Decode(x)
+				(*Decoder).Decode(dec, x)
+				(*Decoder).Decode(dec, &x)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{
+				"encoding/json.Unmarshal":      {1},
+				"encoding/json.Decoder.Decode": {0},
+			}).Merge(Config{Match: MatchExact}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 139, Line: 11, Column: 29}, Line: `Unmarshal([]byte("{}"), x)`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 204, Line: 13, Column: 16}, Line: "dec.Decode(x)", Method: "Decode", Rule: "encoding/json.Decoder.Decode"},
+				{Pos: token.Position{Offset: 254, Line: 14, Column: 48}, Line: `NewDecoder(strings.NewReader("{}")).Decode(x)`, Method: "Decode", Rule: "encoding/json.Decoder.Decode"},
+				{Pos: token.Position{Offset: 292, Line: 16, Column: 16}, Line: "val.Decode(x)", Method: "Decode", Rule: "encoding/json.Decoder.Decode"},
+				{Pos: token.Position{Offset: 322, Line: 17, Column: 28}, Line: "(*Decoder).Decode(dec, x)", Method: "Decode", Rule: "encoding/json.Decoder.Decode", Argument: 1},
+			},
+		},
+	})
+}
+
+func TestOutParamCheckFuncFields(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		type Codec struct {
+			UnmarshalFn func(data []byte, v interface{}) error
+		}
+
+		type client struct {
+			*Codec
+		}
+
+		func main() {
+			var x interface{}
+			c := Codec{}
+			c.UnmarshalFn(nil, x)
+			c.UnmarshalFn(nil, &x)
+			cl := client{&c}
+			cl.UnmarshalFn(nil, x)
+		}
+		`)
+	cfg := argsConfig(map[string][]int{
+		pkgs[0].PkgPath + ".Codec.UnmarshalFn": {1},
+	})
+	cfg.Match = MatchExact
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 211, Line: 15, Column: 23}, Line: "c.UnmarshalFn(nil, x)", Method: "UnmarshalFn", Rule: pkgs[0].PkgPath + ".Codec.UnmarshalFn", Argument: 1},
+		{Pos: token.Position{Offset: 283, Line: 18, Column: 24}, Line: "cl.UnmarshalFn(nil, x)", Method: "UnmarshalFn", Rule: pkgs[0].PkgPath + ".Codec.UnmarshalFn", Argument: 1},
+	}, run(pkgs, cfg))
+}
+
+func TestOutParamCheckArgValues(t *testing.T) {
+	runOutParamTestCases(t, []outParamTestCase{
+		{
+			name: "not addressable",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type config struct{}
+
+			func newConfig() config { return config{} }
+
+			func main() {
+				data := []byte("{}")
+				m := map[string]config{}
+				var c config
+				json.Unmarshal(data, m["key"])
+				json.Unmarshal(data, newConfig())
+				json.Unmarshal(data, c)
+				json.Unmarshal(data, config{})
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 242, Line: 16, Column: 26}, Line: `json.Unmarshal(data, m["key"])`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "is not addressable; assign it to a variable first, then pass its address"},
+				{Pos: token.Position{Offset: 277, Line: 17, Column: 26}, Line: "json.Unmarshal(data, newConfig())", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "is not addressable; assign it to a variable first, then pass its address"},
+				{Pos: token.Position{Offset: 315, Line: 18, Column: 26}, Line: "json.Unmarshal(data, c)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 343, Line: 19, Column: 26}, Line: "json.Unmarshal(data, config{})", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "dereferenced pointers",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type config struct {
+				Name string
+			}
+
+			type holder struct {
+				cfg *config
+			}
+
+			func main() {
+				data := []byte("{}")
+				var c config
+				p := &c
+				h := holder{cfg: p}
+				pp := &p
+				json.Unmarshal(data, *p)
+				json.Unmarshal(data, (*h.cfg))
+				json.Unmarshal(data, *pp)
+				json.Unmarshal(data, *&c)
+				json.Unmarshal(data, p)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 281, Line: 22, Column: 26}, Line: "json.Unmarshal(data, *p)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "dereferences p, so the value is decoded into a copy and lost; pass p instead"},
+				{Pos: token.Position{Offset: 310, Line: 23, Column: 26}, Line: "json.Unmarshal(data, (*h.cfg))", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "dereferences h.cfg, so the value is decoded into a copy and lost; pass h.cfg instead"},
+			},
+		},
+		{
+			name: "pointer types",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type config struct {
+				Target *map[string]string
+			}
+
+			func DecodeRef(data []byte, v interface{}) error { return nil }
+
+			func main() {
+				data := []byte("{}")
+				cfg := config{Target: &map[string]string{}}
+				json.Unmarshal(data, cfg.Target)
+				ptrs := []*config{{}}
+				json.Unmarshal(data, ptrs[0])
+				m := map[string]string{}
+				json.Unmarshal(data, m)
+				DecodeRef(data, m)
+				DecodeRef(data, ptrs)
+				DecodeRef(data, cfg)
+			}
+		`,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"encoding/json.Unmarshal": {Args: indexArgs(1)},
+					"DecodeRef":               {Args: []Arg{{Index: 1, Kind: ArgKindRef}}},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 425, Line: 21, Column: 26}, Line: "json.Unmarshal(data, m)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 497, Line: 24, Column: 21}, Line: "DecodeRef(data, cfg)", Method: "DecodeRef", Rule: "DecodeRef", Argument: 1},
+			},
+		},
+		{
+			name: "pointer fields",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"os"
+			)
+
+			type Config struct{}
+
+			type base struct {
+				Target *Config
+			}
+
+			type settings struct {
+				*base
+				Any   interface{}
+				Value Config
+			}
+
+			func main() {
+				data := []byte("{}")
+				s := settings{base: &base{}}
+				json.Unmarshal(data, s.Target)
+				json.Unmarshal(data, s.base.Target)
+				json.Unmarshal(data, (&s).Target)
+				json.Unmarshal(data, os.Stdout)
+				json.Unmarshal(data, s.Any)
+				json.Unmarshal(data, s.Value)
+			}
+		`,
+			cfg: Config{Rules: map[string]*Rule{"encoding/json.Unmarshal": {Args: indexArgs(1)}}},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 467, Line: 28, Column: 26}, Line: "json.Unmarshal(data, s.Any)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 499, Line: 29, Column: 26}, Line: "json.Unmarshal(data, s.Value)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "pointer elements",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type Config struct{}
+
+			func main() {
+				data := []byte("{}")
+				targets := []*Config{{}}
+				byName := map[string]*Config{"a": {}}
+				arr := [1]*Config{{}}
+				ptrArr := &arr
+				anys := []interface{}{Config{}}
+				values := map[string]Config{"a": {}}
+				for i := range targets {
+					json.Unmarshal(data, targets[i])
+				}
+				json.Unmarshal(data, byName["a"])
+				json.Unmarshal(data, arr[0])
+				json.Unmarshal(data, ptrArr[0])
+				json.Unmarshal(data, targets[0:1][0])
+				json.Unmarshal(data, anys[0])
+				json.Unmarshal(data, values["a"])
+			}
+		`,
+			cfg: Config{Rules: map[string]*Rule{"encoding/json.Unmarshal": {Args: indexArgs(1)}}},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 563, Line: 25, Column: 26}, Line: "json.Unmarshal(data, anys[0])", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 597, Line: 26, Column: 26}, Line: `json.Unmarshal(data, values["a"])`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "is not addressable; assign it to a variable first, then pass its address"},
+			},
+		},
+		{
+			name: "pointer calls",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type Config struct{}
+
+			type registry struct{}
+
+			func (registry) get(name string) *Config { return &Config{} }
+
+			func cfgFor(env string) *Config { return &Config{} }
+
+			func newValue[T any]() *T { return new(T) }
+
+			func valueFor(env string) Config { return Config{} }
+
+			func main() {
+				data := []byte("{}")
+				factory := cfgFor
+				json.Unmarshal(data, cfgFor("prod"))
+				json.Unmarshal(data, registry{}.get("prod"))
+				json.Unmarshal(data, newValue[Config]())
+				json.Unmarshal(data, factory("prod"))
+				json.Unmarshal(data, func() *Config { return &Config{} }())
+				json.Unmarshal(data, valueFor("prod"))
+			}
+		`,
+			cfg: Config{Rules: map[string]*Rule{"encoding/json.Unmarshal": {Args: indexArgs(1)}}},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 666, Line: 28, Column: 26}, Line: `json.Unmarshal(data, valueFor("prod"))`, Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "is not addressable; assign it to a variable first, then pass its address"},
+			},
+		},
+		{
+			name: "conversions",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+				"unsafe"
+			)
+
+			type Config struct{}
+
+			type ConfigPtr *Config
+
+			func main() {
+				data := []byte("{}")
+				var c Config
+				p := unsafe.Pointer(&c)
+				json.Unmarshal(data, (*Config)(p))
+				json.Unmarshal(data, unsafe.Pointer(&c))
+				json.Unmarshal(data, p)
+				json.Unmarshal(data, ConfigPtr(&c))
+				json.Unmarshal(data, interface{}(&c))
+				json.Unmarshal(data, (interface{})(unsafe.Pointer(&c)))
+				json.Unmarshal(data, interface{}(c))
+				json.Unmarshal(data, uintptr(p))
+			}
+		`,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"encoding/json.Unmarshal": {Args: []Arg{{Index: 1, Kind: ArgKindPtrToStruct}}},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 487, Line: 23, Column: 26}, Line: "json.Unmarshal(data, interface{}(c))", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "is not addressable; assign it to a variable first, then pass its address"},
+				{Pos: token.Position{Offset: 528, Line: 24, Column: 26}, Line: "json.Unmarshal(data, uintptr(p))", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "is not addressable; assign it to a variable first, then pass its address"},
+			},
+		},
+		{
+			name: "pointer results",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type target struct{}
+
+			func newTarget() *target { return &target{} }
+
+			func main() {
+				data := []byte("{}")
+				p := newTarget()
+				json.Unmarshal(data, p)
+				var i interface{} = newTarget()
+				json.Unmarshal(data, i)
+				var j interface{} = target{}
+				json.Unmarshal(data, j)
+				var k, l interface{} = &target{}, target{}
+				json.Unmarshal(data, k)
+				json.Unmarshal(data, l)
+			}
+		`,
+			cfg: Config{Rules: map[string]*Rule{"encoding/json.Unmarshal": {Args: indexArgs(1)}}},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 344, Line: 19, Column: 26}, Line: "json.Unmarshal(data, j)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 447, Line: 22, Column: 26}, Line: "json.Unmarshal(data, l)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "reassignments",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type config struct{}
+
+			func decode(data []byte, cond bool, q interface{}, ch chan int) {
+				var x interface{}
+				p := &x
+				json.Unmarshal(data, p)
+				var r interface{} = &config{}
+				r = q
+				json.Unmarshal(data, r)
+				r = &config{}
+				json.Unmarshal(data, r)
+				s := r
+				json.Unmarshal(data, s)
+
+				var t interface{}
+				if cond {
+					t = &config{}
+				} else {
+					t = new(config)
+				}
+				json.Unmarshal(data, t)
+
+				var u interface{} = &config{}
+				if cond {
+					u = config{}
+				}
+				json.Unmarshal(data, u)
+
+				var w interface{}
+				json.Unmarshal(data, w)
+
+				var sw interface{} = config{}
+				switch {
+				case cond:
+					sw = &config{}
+					json.Unmarshal(data, sw)
+				default:
+					json.Unmarshal(data, sw)
+				}
+
+				var sel interface{} = config{}
+				select {
+				case <-ch:
+					sel = &config{}
+					json.Unmarshal(data, sel)
+				default:
+					json.Unmarshal(data, sel)
+				}
+			}
+
+			func main() {}
+		`,
+			cfg: Config{Rules: map[string]*Rule{"encoding/json.Unmarshal": {Args: indexArgs(1)}}},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 281, Line: 16, Column: 26}, Line: "json.Unmarshal(data, r)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 591, Line: 34, Column: 26}, Line: "json.Unmarshal(data, u)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 642, Line: 37, Column: 26}, Line: "json.Unmarshal(data, w)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 797, Line: 45, Column: 27}, Line: "json.Unmarshal(data, sw)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 962, Line: 54, Column: 27}, Line: "json.Unmarshal(data, sel)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "new",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type config struct{}
+
+			func main() {
+				data := []byte("{}")
+				json.Unmarshal(data, new(config))
+				json.Unmarshal(data, (new)(map[string]string))
+				var v interface{} = new(config)
+				json.Unmarshal(data, v)
+				json.Unmarshal(data, *new(config))
+			}
+		`,
+			cfg: Config{
+				Rules: map[string]*Rule{
+					"encoding/json.Unmarshal": {Args: []Arg{{Index: 1, Kind: ArgKindPtrToStruct}}},
+				},
+			},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 186, Line: 13, Column: 26}, Line: "json.Unmarshal(data, (new)(map[string]string))", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "must be a pointer to a struct, was *map[string]string"},
+				{Pos: token.Position{Offset: 301, Line: 16, Column: 26}, Line: "json.Unmarshal(data, *new(config))", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "dereferences new(config), so the value is decoded into a copy and lost; pass new(config) instead"},
+			},
+		},
+		{
+			name: "multi-value assignments",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			type target struct{}
+
+			func build() (interface{}, interface{}, error) { return nil, nil, nil }
+
+			func buildPtr() (int, *target, error) { return 0, &target{}, nil }
+
+			func main() {
+				data := []byte("{}")
+				var x interface{}
+				a, p := 0, &x
+				json.Unmarshal(data, p)
+				q, b := &x, x
+				json.Unmarshal(data, q)
+				json.Unmarshal(data, b)
+				_, r, _ := buildPtr()
+				json.Unmarshal(data, r)
+				var _, s, _ interface{} = buildPtr()
+				json.Unmarshal(data, s)
+				_, u, _ := build()
+				json.Unmarshal(data, u)
+				_ = a
+			}
+		`,
+			cfg: Config{Rules: map[string]*Rule{"encoding/json.Unmarshal": {Args: indexArgs(1)}}},
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 409, Line: 21, Column: 26}, Line: "json.Unmarshal(data, b)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 583, Line: 27, Column: 26}, Line: "json.Unmarshal(data, u)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+	})
+}
+
+func TestOutParamCheckDeclaredValues(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "vars.go"), []byte(`package main
+
+type config struct{}
+
+var target interface{} = &config{}
+
+var value interface{} = config{}
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "main.go"), []byte(`package main
+
+import (
+	"encoding/json"
+)
+
+func decode(data []byte, param interface{}) {
+	json.Unmarshal(data, target)
+	json.Unmarshal(data, value)
+	json.Unmarshal(data, param)
+	var x interface{}
+	p := &x
+	{
+		p := x
+		json.Unmarshal(data, p)
+	}
+	json.Unmarshal(data, p)
+}
+
+func main() {}
+`), 0644))
+	pkgs, err := load([]string{"./" + tmpDir}, nil, nil, testsNone, nil)
+	require.NoError(t, err)
+
+	cfg := Config{
+		Rules: map[string]*Rule{
+			"encoding/json.Unmarshal": {Args: indexArgs(1)},
+		},
+	}
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 141, Line: 9, Column: 23}, Line: "json.Unmarshal(data, value)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 170, Line: 10, Column: 23}, Line: "json.Unmarshal(data, param)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 240, Line: 15, Column: 24}, Line: "json.Unmarshal(data, p)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+	}, run(pkgs, cfg))
+}
+
+func TestOutParamCheckSSAMode(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		type config struct{}
+
+		func decode(data []byte, cond bool) {
+			var v interface{}
+			if cond {
+				v = &config{}
+			} else {
+				v = new(config)
+			}
+			json.Unmarshal(data, v)
+
+			var w interface{} = &config{}
+			w = config{}
+			json.Unmarshal(data, w)
+
+			var x interface{}
+			for i := 0; i < 3; i++ {
+				x = &config{}
+			}
+			json.Unmarshal(data, x)
+
+			var y interface{} = &config{}
+			for i := 0; i < 3; i++ {
+				json.Unmarshal(data, y)
+				y = config{}
+			}
+
+			p := &config{}
+			json.Unmarshal(data, p)
+			json.Unmarshal(data, config{})
+		}
+
+		func (c *config) load(data []byte) {
+			var v interface{} = c
+			for i := 0; i < 3; i++ {
+				json.Unmarshal(data, v)
+				v = *c
+			}
+		}
+
+		func main() {
+			go func(data []byte) {
+				var v interface{} = &config{}
+				for i := 0; i < 3; i++ {
+					json.Unmarshal(data, v)
+					v = config{}
+				}
+			}(nil)
+		}
+		`)
+	cfg := Config{
+		Rules: map[string]*Rule{
+			"encoding/json.Unmarshal": {Args: indexArgs(1)},
+		},
+	}
+	pkgCfg := func(*packages.Package) Config {
+		return cfg
+	}
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 306, Line: 21, Column: 25}, Line: "json.Unmarshal(data, w)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 591, Line: 37, Column: 25}, Line: "json.Unmarshal(data, config{})", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+	}, runWithConfigs(pkgs, pkgCfg, nil, analysis{}), "AST")
+	// the assignments of y and of the v of the method and the function literal in the previous iteration of the loop
+	// reach the calls
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 306, Line: 21, Column: 25}, Line: "json.Unmarshal(data, w)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 496, Line: 31, Column: 26}, Line: "json.Unmarshal(data, y)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 591, Line: 37, Column: 25}, Line: "json.Unmarshal(data, config{})", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 723, Line: 43, Column: 26}, Line: "json.Unmarshal(data, v)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 878, Line: 52, Column: 27}, Line: "json.Unmarshal(data, v)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+	}, runWithConfigs(pkgs, pkgCfg, nil, analysis{ssaCalls: buildSSACalls(pkgs)}), "SSA")
+}
+
+func TestOutParamCheckInferWrappers(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		type Config struct{}
+
+		type client struct{}
+
+		func decode(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+		func decodeTwice(data []byte, v interface{}) error { return decode(data, (v)) }
+
+		func (client) Get(path string, out interface{}) error { return decodeTwice(nil, out) }
+
+		func reassigned(data []byte, v interface{}) error {
+			v = Config{}
+			return json.Unmarshal(data, v)
+		}
+
+		func main() {
+			var c Config
+			decode(nil, c)
+			decode(nil, &c)
+			decodeTwice(nil, c)
+			client{}.Get("/config", c)
+			client{}.Get("/config", &c)
+			reassigned(nil, c)
+		}
+		`)
+	cfg := Config{
+		Rules: map[string]*Rule{
+			"encoding/json.Unmarshal": {Args: indexArgs(1)},
+		},
+	}
+	pkgCfg := func(*packages.Package) Config {
+		return cfg
+	}
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 178, Line: 12, Column: 79}, Line: "func decode(data []byte, v interface{}) error { return json.Unmarshal(data, v) }", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 458, Line: 20, Column: 32}, Line: "return json.Unmarshal(data, v)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+	}, runWithConfigs(pkgs, pkgCfg, nil, analysis{}), "not inferred")
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 458, Line: 20, Column: 32}, Line: "return json.Unmarshal(data, v)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 513, Line: 25, Column: 16}, Line: "decode(nil, c)", Method: "decode", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 555, Line: 27, Column: 21}, Line: "decodeTwice(nil, c)", Method: "decodeTwice", Rule: "encoding/json.Unmarshal", Argument: 1},
+		{Pos: token.Position{Offset: 585, Line: 28, Column: 28}, Line: `client{}.Get("/config", c)`, Method: "Get", Rule: "encoding/json.Unmarshal", Argument: 1},
+	}, runWithConfigs(pkgs, pkgCfg, nil, analysis{wrappers: inferWrappers(pkgs, pkgCfg)}), "inferred")
+}
+
+func TestOutParamCheckDirectives(t *testing.T) {
+	ignoreFileInput := `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		//outparamcheck:ignore-file only applies before the package clause
+
+		func main() {
+			var x interface{}
+			json.Unmarshal(nil, x)
+		}
+		`
+
+	runOutParamTestCases(t, []outParamTestCase{
+		{
+			name: "nolint",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			func main() {
+				var x interface{}
+				json.Unmarshal(nil, x) //nolint:outparamcheck
+				json.Unmarshal(nil, x) //nolint
+				json.Unmarshal(nil, x) //nolint:errcheck
+				//nolint:outparamcheck
+				json.Unmarshal(nil, x)
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 205, Line: 12, Column: 25}, Line: "json.Unmarshal(nil, x) //nolint:errcheck", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 277, Line: 14, Column: 25}, Line: "json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name: "ignore directive",
+			input: `
+			package main
+
+			import (
+				"encoding/json"
+			)
+
+			func main() {
+				var x interface{}
+				//outparamcheck:ignore x is decoded by reflection
+				json.Unmarshal(nil,
+					x)
+				json.Unmarshal(nil, x)
+				//outparamcheck:ignore
+				json.Unmarshal(nil, x)
+
+				// the directive applies to the entire statement
+				//outparamcheck:ignore legacy code
+				if err := json.Unmarshal(nil, x); err != nil {
+					json.Unmarshal(nil, x)
+				}
+			}
+		`,
+			cfg: argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 205, Line: 13, Column: 25}, Line: "json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+				{Pos: token.Position{Offset: 212, Line: 14, Column: 5}, Line: "//outparamcheck:ignore", Problem: missingReasonProblem, Check: invalidDirectiveCheck},
+				{Pos: token.Position{Offset: 259, Line: 15, Column: 25}, Line: "json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name:  "ignore-file directive after generated code comment",
+			input: "// Code generated by bindgen. DO NOT EDIT.\n\n//outparamcheck:ignore-file generated bindings\n" + ignoreFileInput,
+			cfg:   argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+		},
+		{
+			name:  "ignore-file directive without reason",
+			input: "//outparamcheck:ignore-file\n" + ignoreFileInput,
+			cfg:   argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+		},
+		{
+			name:  "misspelled ignore-file directive",
+			input: "//outparamcheck:ignore-files\n" + ignoreFileInput,
+			cfg:   argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 211, Line: 13, Column: 24}, Line: "json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+		{
+			name:  "no ignore-file directive",
+			input: "" + ignoreFileInput,
+			cfg:   argsConfig(map[string][]int{"encoding/json.Unmarshal": {1}}),
+			expected: []OutParamError{
+				{Pos: token.Position{Offset: 182, Line: 12, Column: 24}, Line: "json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+			},
+		},
+	})
+}
+
+func TestOutParamCheckSkipFiles(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		func main() {
+			var x interface{}
+			json.Unmarshal(nil, x)
+		}
+		`)
+	cfg := argsConfig(map[string][]int{
+		"encoding/json.Unmarshal": {1},
+	})
+	unskipped := []OutParamError{
+		{Pos: token.Position{Offset: 112, Line: 10, Column: 24}, Line: "json.Unmarshal(nil, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+	}
+	for i, tc := range []struct {
+		skipFiles []string
+		want      []OutParamError
+	}{
+		{nil, unskipped},
+		{[]string{"main.go"}, nil},
+		{[]string{`/main\.go$`}, nil},
+		{[]string{"*_test.go"}, unskipped},
+	} {
+		skipFiles, err := newFileFilter(tc.skipFiles)
+		require.NoError(t, err)
+		errs := runWithConfigs(pkgs, func(*packages.Package) Config {
+			return cfg
+		}, skipFiles, analysis{})
+		assertOutParamErrors(t, pkgs, tc.want, errs, "Case %d", i)
+	}
+}
+
+func TestOutParamCheckExcludePackages(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+		)
+
+		func main() {
+			j := []byte("...")
+			var x interface{}
+			json.Unmarshal(j, x)
+		}
+		`)
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 132, Line: 11, Column: 22}, Line: "json.Unmarshal(j, x)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1},
+	}, run(pkgs, defaultCfg))
+
+	cfg := defaultCfg.Merge(Config{
+		ExcludePackages: []string{path.Dir(pkgs[0].PkgPath) + "/..."},
+	})
+	assert.Empty(t, run(pkgs, cfg))
+}
+
+func TestRequiresAddr(t *testing.T) {
+	for i, tc := range []struct {
+		errs []OutParamError
+		want bool
+	}{
+		{nil, false},
+		{[]OutParamError{{Method: "Unmarshal", Argument: 1}}, true},
+		{[]OutParamError{{Method: "Unmarshal", Argument: 1, Severity: SeverityWarning}}, false},
+		{[]OutParamError{{Method: "Unmarshal", Argument: 1, Problem: nilProblem}}, false},
+		{[]OutParamError{{Problem: "x is assigned but never read", Check: CheckUnusedAssignment}}, false},
+		{[]OutParamError{{Problem: "x is assigned but never read", Check: CheckUnusedAssignment}, {Method: "Unmarshal", Argument: 1}}, true},
+	} {
+		assert.Equal(t, tc.want, requiresAddr(tc.errs), "Case %d", i)
+	}
+}
+
+// loadTestPackage writes the provided program to a new directory within the provided directory and returns the
+// loaded package for it.
+func loadTestPackage(t *testing.T, dir, input string) []*packages.Package {
+	// write program to temp file
+	currCaseDir, err := ioutil.TempDir(dir, "")
+	require.NoError(t, err)
+
+	fpath := path.Join(currCaseDir, "main.go")
+	err = ioutil.WriteFile(fpath, []byte(input), 0644)
+	require.NoError(t, err)
+
+	// load package for program
+	pkgs, err := packages.Load(&packages.Config{
+		// building SSA for the program requires the types of its dependencies, which LoadSyntax does not load
+		Mode: packages.LoadAllSyntax | packages.NeedModule,
+		// test programs import modules that are vendored by the module, which must be loaded without network access
+		BuildFlags: []string{"-mod=vendor"},
+	}, "./"+currCaseDir)
+	require.NoError(t, err)
+	return pkgs
+}
+
+// outParamTestCase is a program, the configuration that it is checked with and the errors that are expected for it in
+// the order of their positions. The file names of the positions of the expected errors are filled in when it is run.
+type outParamTestCase struct {
+	name     string
+	input    string
+	cfg      Config
+	expected []OutParamError
+}
+
+// runOutParamTestCases checks the program of each of the provided test cases with its configuration and asserts that
+// the expected errors are reported.
+func runOutParamTestCases(t *testing.T, tcs []outParamTestCase) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	for _, tc := range tcs {
+		pkgs := loadTestPackage(t, tmpDir, tc.input)
+		assertOutParamErrors(t, pkgs, tc.expected, run(pkgs, tc.cfg), tc.name)
+	}
+}
+
+// assertOutParamErrors asserts that the provided errors, which were reported for the provided test packages, are the
+// expected errors once they are sorted by position. The file name of the position of every expected error is set to
+// the main.go file of the packages.
+func assertOutParamErrors(t *testing.T, pkgs []*packages.Package, expected, errs []OutParamError, msgAndArgs ...interface{}) {
+	require.NotEmpty(t, pkgs)
+	for i := range expected {
+		expected[i].Pos.Filename = path.Join(path.Dir(pkgs[0].GoFiles[0]), "main.go")
 	}
+	sort.Sort(byLocation(errs))
+	assert.Equal(t, expected, errs, msgAndArgs...)
 }
@@ -0,0 +1,66 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// packagePatterns caches the compiled package patterns, which are compiled when the configuration that contains them is
+// parsed.
+var packagePatterns sync.Map // map[string]packagePattern
+
+// packagePattern matches import paths. In a pattern, "..." matches any string (including the empty string and strings
+// that contain slashes) and "*" matches any string that does not contain a slash. A pattern that ends in "/..." also
+// matches the path that precedes it, so "example.com/legacy/..." matches "example.com/legacy". A pattern that does not
+// contain a slash is also matched against the last element of the path, so "*_generated" matches
+// "example.com/api_generated".
+type packagePattern struct {
+	pattern string
+	regexp  *regexp.Regexp
+}
+
+func newPackagePattern(pattern string) packagePattern {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\.\.\.`, `.*`)
+	expr = strings.ReplaceAll(expr, `\*`, `[^/]*`)
+	if strings.HasSuffix(expr, `/.*`) {
+		expr = strings.TrimSuffix(expr, `/.*`) + `(/.*)?`
+	}
+	if !strings.Contains(pattern, "/") {
+		expr = `(.*/)?` + expr
+	}
+	return packagePattern{
+		pattern: pattern,
+		regexp:  regexp.MustCompile(`^` + expr + `$`),
+	}
+}
+
+func (p packagePattern) matches(pkgPath string) bool {
+	return p.regexp.MatchString(pkgPath)
+}
+
+// compiledPackagePattern returns the compiled form of the provided pattern, which is compiled and cached if it has not
+// been compiled before.
+func compiledPackagePattern(pattern string) packagePattern {
+	if p, ok := packagePatterns.Load(pattern); ok {
+		return p.(packagePattern)
+	}
+	p := newPackagePattern(pattern)
+	packagePatterns.Store(pattern, p)
+	return p
+}
+
+// matchesAnyPackagePattern returns true if the provided import path matches any of the provided patterns.
+func matchesAnyPackagePattern(patterns []string, pkgPath string) bool {
+	for _, pattern := range patterns {
+		if compiledPackagePattern(pattern).matches(pkgPath) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackagePattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		pkgPath string
+		matches bool
+	}{
+		{"example.com/legacy/...", "example.com/legacy", true},
+		{"example.com/legacy/...", "example.com/legacy/db/v2", true},
+		{"example.com/legacy/...", "example.com/legacyapi", false},
+		{"example.com/.../internal", "example.com/a/b/internal", true},
+		{"example.com/.../internal", "example.com/a/b/internal/c", false},
+		{"example.com/*/internal", "example.com/a/internal", true},
+		{"example.com/*/internal", "example.com/a/b/internal", false},
+		{"*_generated", "example.com/api_generated", true},
+		{"*_generated", "api_generated", true},
+		{"*_generated", "example.com/api_generated/client", false},
+		{"example.com/api", "example.com/api", true},
+		{"example.com/api", "example.com/api/v2", false},
+		{"api", "example.com/api", true},
+	} {
+		assert.Equal(t, tc.matches, newPackagePattern(tc.pattern).matches(tc.pkgPath), "%s %s", tc.pattern, tc.pkgPath)
+	}
+}
+
+func TestParseCfgCompilesPackagePatterns(t *testing.T) {
+	_, err := parseCfg(`{
+		"version": 2,
+		"rules": {
+			"Decoder.Decode": {"args": [0], "packages": ["example.com/compiled/codec/..."]}
+		},
+		"excludePackages": ["example.com/compiled/legacy/..."]
+	}`)
+	require.NoError(t, err)
+
+	for _, pattern := range []string{"example.com/compiled/codec/...", "example.com/compiled/legacy/..."} {
+		p, ok := packagePatterns.Load(pattern)
+		require.True(t, ok, pattern)
+		assert.Equal(t, newPackagePattern(pattern), p, pattern)
+	}
+	assert.True(t, matchesAnyPackagePattern([]string{"example.com/compiled/legacy/..."}, "example.com/compiled/legacy/db"))
+}
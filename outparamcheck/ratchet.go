@@ -0,0 +1,121 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// issueCeilings are the maximum numbers of errors that the packages may have, keyed by import path. A package that does
+// not have a ceiling may not have any errors. Ceilings are only ever lowered when they are updated, so the number of
+// errors of a codebase can be ratcheted down over time.
+type issueCeilings struct {
+	Ceilings map[string]int `json:"ceilings"`
+}
+
+// readCeilings returns the ceilings in the file with the provided path. If mayNotExist is true, empty ceilings are
+// returned if the file does not exist.
+func readCeilings(path string, mayNotExist bool) (issueCeilings, error) {
+	ceilingsBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		if mayNotExist && os.IsNotExist(err) {
+			return issueCeilings{}, nil
+		}
+		return issueCeilings{}, errors.Wrapf(err, "failed to read ceilings %s", path)
+	}
+	var c issueCeilings
+	if err := json.Unmarshal(ceilingsBytes, &c); err != nil {
+		return issueCeilings{}, errors.Wrapf(err, "failed to parse ceilings %s", path)
+	}
+	for pkgPath, ceiling := range c.Ceilings {
+		if ceiling < 0 {
+			return issueCeilings{}, errors.Errorf("ceilings %s: ceiling of %s must not be negative, was %d", path, pkgPath, ceiling)
+		}
+	}
+	return c, nil
+}
+
+func writeCeilings(path string, c issueCeilings) error {
+	ceilingsBytes, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal ceilings")
+	}
+	if err := ioutil.WriteFile(path, append(ceilingsBytes, '\n'), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write ceilings %s", path)
+	}
+	return nil
+}
+
+// countByPackage returns the number of the provided errors with SeverityError in each of the provided packages keyed by
+// import path, which includes the packages without errors. An error that is reported for multiple variants of a
+// package, such as the package and its test variant, is counted once.
+func countByPackage(pkgs []*packages.Package, errs []OutParamError) map[string]int {
+	filePkgs := make(map[string]string)
+	counts := make(map[string]int)
+	for _, pkg := range pkgs {
+		counts[pkg.PkgPath] = 0
+		for _, file := range pkg.Syntax {
+			filePkgs[pkg.Fset.Position(file.Pos()).Filename] = pkg.PkgPath
+		}
+	}
+	type errKey struct {
+		pos      string
+		argument int
+	}
+	counted := make(map[errKey]bool)
+	for _, err := range errs {
+		key := errKey{pos: err.Pos.String(), argument: err.Argument}
+		if err.Severity != SeverityError || counted[key] {
+			continue
+		}
+		counted[key] = true
+		counts[filePkgs[err.Pos.Filename]]++
+	}
+	return counts
+}
+
+// exceeded returns a description of every package whose number of errors exceeds its ceiling, sorted by import path.
+func (c issueCeilings) exceeded(counts map[string]int) []string {
+	var exceeded []string
+	for pkgPath, count := range counts {
+		if ceiling := c.Ceilings[pkgPath]; count > ceiling {
+			exceeded = append(exceeded, fmt.Sprintf("%s has %s, which exceeds its ceiling of %d", pkgPath, plural(count, "error", "errors"), ceiling))
+		}
+	}
+	sort.Strings(exceeded)
+	return exceeded
+}
+
+// ratchet returns the ceilings lowered to the provided numbers of errors, which only affects the packages that have a
+// number of errors. Ceilings are never raised and packages that do not have a ceiling are not added unless the ceilings
+// are empty, in which case the ceilings are initialized to the provided numbers of errors. Packages whose ceiling is
+// lowered to zero are removed.
+func (c issueCeilings) ratchet(counts map[string]int) issueCeilings {
+	ratcheted := issueCeilings{Ceilings: make(map[string]int)}
+	if len(c.Ceilings) == 0 {
+		for pkgPath, count := range counts {
+			if count > 0 {
+				ratcheted.Ceilings[pkgPath] = count
+			}
+		}
+		return ratcheted
+	}
+	for pkgPath, ceiling := range c.Ceilings {
+		if count, ok := counts[pkgPath]; ok && count < ceiling {
+			ceiling = count
+		}
+		if ceiling > 0 {
+			ratcheted.Ceilings[pkgPath] = ceiling
+		}
+	}
+	return ratcheted
+}
@@ -0,0 +1,74 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"path"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueCeilingsRatchet(t *testing.T) {
+	c := issueCeilings{Ceilings: map[string]int{
+		"github.com/org/a": 5,
+		"github.com/org/b": 2,
+		"github.com/org/c": 3,
+		"github.com/org/d": 4,
+	}}
+	counts := map[string]int{
+		// lowered
+		"github.com/org/a": 3,
+		// not raised
+		"github.com/org/b": 4,
+		// removed
+		"github.com/org/c": 0,
+		// not added
+		"github.com/org/e": 1,
+	}
+	assert.Equal(t, issueCeilings{Ceilings: map[string]int{
+		"github.com/org/a": 3,
+		"github.com/org/b": 2,
+		// the package was not checked
+		"github.com/org/d": 4,
+	}}, c.ratchet(counts))
+	assert.Equal(t, []string{
+		"github.com/org/b has 4 errors, which exceeds its ceiling of 2",
+		"github.com/org/e has 1 error, which exceeds its ceiling of 0",
+	}, c.exceeded(counts))
+
+	// empty ceilings are initialized
+	assert.Equal(t, issueCeilings{Ceilings: map[string]int{
+		"github.com/org/a": 3,
+		"github.com/org/b": 4,
+		"github.com/org/e": 1,
+	}}, issueCeilings{}.ratchet(counts))
+}
+
+func TestCheckCeilings(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	ceilingsPath := path.Join(tmpDir, "ceilings.json")
+	err = checkCeilings(ceilingsPath, false, map[string]int{"github.com/org/a": 2})
+	assert.Error(t, err)
+
+	// the ceilings file is created
+	require.NoError(t, checkCeilings(ceilingsPath, true, map[string]int{"github.com/org/a": 2}))
+	require.NoError(t, checkCeilings(ceilingsPath, false, map[string]int{"github.com/org/a": 2}))
+	err = checkCeilings(ceilingsPath, false, map[string]int{"github.com/org/a": 3})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "github.com/org/a has 3 errors, which exceeds its ceiling of 2")
+
+	// the ceiling is lowered
+	require.NoError(t, checkCeilings(ceilingsPath, true, map[string]int{"github.com/org/a": 1}))
+	c, err := readCeilings(ceilingsPath, false)
+	require.NoError(t, err)
+	assert.Equal(t, issueCeilings{Ceilings: map[string]int{"github.com/org/a": 1}}, c)
+	assert.Error(t, checkCeilings(ceilingsPath, false, map[string]int{"github.com/org/a": 2}))
+}
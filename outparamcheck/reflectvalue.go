@@ -0,0 +1,40 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// reflectValueProblem is the problem that is reported for output parameters that are passed reflect.ValueOf(x) for a
+// value x that is not an address, since such a reflect.Value is neither a pointer nor settable.
+const reflectValueProblem = "holds a copy of the value that cannot be decoded into; pass the reflect.Value of its address instead"
+
+// isReflectValue returns true if the provided type is reflect.Value, which output parameters such as the one of
+// (*gob.Decoder).DecodeValue accept instead of a pointer.
+func isReflectValue(typ types.Type) bool {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "reflect" && obj.Name() == "Value"
+}
+
+// reflectValueOfArg returns the argument of the provided expression if it is a call of reflect.ValueOf, and nil
+// otherwise. Whether other reflect.Value expressions, such as reflect.ValueOf(&x).Elem(), can be decoded into is not
+// known statically.
+func (v *visitor) reflectValueOfArg(expr ast.Expr) ast.Expr {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil
+	}
+	fn := v.calleeFunc(call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" || fn.Name() != "ValueOf" {
+		return nil
+	}
+	return call.Args[0]
+}
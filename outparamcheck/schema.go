@@ -0,0 +1,400 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed schema.json
+var configSchemaJSON []byte
+
+var configSchema = mustParseSchema(configSchemaJSON)
+
+// ConfigSchema returns the JSON Schema that describes the configuration format.
+func ConfigSchema() []byte {
+	return append([]byte(nil), configSchemaJSON...)
+}
+
+// schema is a JSON Schema. Only the subset of keywords used by the configuration schema is supported: annotations
+// such as "title" and "description" are ignored and unknown keywords are rejected when the schema is parsed.
+type schema struct {
+	Schema               string             `json:"$schema"`
+	ID                   string             `json:"$id"`
+	Title                string             `json:"title"`
+	Description          string             `json:"description"`
+	Ref                  string             `json:"$ref"`
+	Definitions          map[string]*schema `json:"definitions"`
+	Type                 schemaTypes        `json:"type"`
+	Enum                 []interface{}      `json:"enum"`
+	OneOf                []*schema          `json:"oneOf"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	PropertyNames        *schema            `json:"propertyNames"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	UniqueItems          bool               `json:"uniqueItems"`
+	MinLength            *int               `json:"minLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *json.Number       `json:"minimum"`
+
+	// never is true for the boolean schema "false", which no value satisfies.
+	never   bool
+	pattern *regexp.Regexp
+	// ref is the schema referenced by Ref.
+	ref *schema
+}
+
+func mustParseSchema(schemaJSON []byte) *schema {
+	var s schema
+	dec := json.NewDecoder(bytes.NewReader(schemaJSON))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		panic(errors.Wrapf(err, "invalid schema"))
+	}
+	if err := s.resolveRefs(&s); err != nil {
+		panic(errors.Wrapf(err, "invalid schema"))
+	}
+	return &s
+}
+
+// resolveRefs resolves the references of the schema and of all of its subschemas against the provided root schema.
+// Only references to the definitions of the root schema ("#/definitions/name") are supported.
+func (s *schema) resolveRefs(root *schema) error {
+	if s.Ref != "" {
+		const prefix = "#/definitions/"
+		if !strings.HasPrefix(s.Ref, prefix) {
+			return errors.Errorf("unsupported reference %s", s.Ref)
+		}
+		ref, ok := root.Definitions[strings.TrimPrefix(s.Ref, prefix)]
+		if !ok {
+			return errors.Errorf("unresolved reference %s", s.Ref)
+		}
+		s.ref = ref
+	}
+
+	var subschemas []*schema
+	subschemas = append(subschemas, s.OneOf...)
+	for _, sub := range s.Definitions {
+		subschemas = append(subschemas, sub)
+	}
+	for _, sub := range s.Properties {
+		subschemas = append(subschemas, sub)
+	}
+	subschemas = append(subschemas, s.PropertyNames, s.AdditionalProperties, s.Items)
+	for _, sub := range subschemas {
+		if sub == nil {
+			continue
+		}
+		if err := sub.resolveRefs(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// definition returns the definition of the schema with the provided name. Panics if the definition does not exist.
+func (s *schema) definition(name string) *schema {
+	def, ok := s.Definitions[name]
+	if !ok {
+		panic(errors.Errorf("schema does not contain definition %s", name))
+	}
+	return def
+}
+
+// resolved returns the schema referenced by the schema if it is a reference and the schema itself otherwise.
+func (s *schema) resolved() *schema {
+	if s.ref != nil {
+		return s.ref.resolved()
+	}
+	return s
+}
+
+func (s *schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = schema{never: !b}
+		return nil
+	}
+
+	type schemaAlias schema
+	var alias schemaAlias
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&alias); err != nil {
+		return err
+	}
+	*s = schema(alias)
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return errors.Wrapf(err, "invalid pattern %s", s.Pattern)
+		}
+		s.pattern = pattern
+	}
+	return nil
+}
+
+// decodeJSONValue decodes the provided JSON into a value that can be validated against a schema.
+func decodeJSONValue(data []byte) (interface{}, error) {
+	var val interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&val); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse JSON")
+	}
+	return val, nil
+}
+
+// validate validates the provided value, which must be the result of decoding JSON with numbers decoded as
+// json.Number, and returns a description of every violation. Each description is prefixed by the path of the
+// offending value relative to the value being validated.
+func (s *schema) validate(path string, val interface{}) []string {
+	if s.ref != nil {
+		return s.ref.validate(path, val)
+	}
+	if s.never {
+		return []string{describePath(path) + "is not allowed"}
+	}
+	if len(s.Type) > 0 && !s.Type.matches(val) {
+		return []string{fmt.Sprintf("%smust be %s, was %s", describePath(path), s.Type, describeValue(val))}
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, val) {
+		var allowed []string
+		for _, e := range s.Enum {
+			allowed = append(allowed, describeValue(e))
+		}
+		return []string{fmt.Sprintf("%smust be one of %s, was %s", describePath(path), strings.Join(allowed, ", "), describeValue(val))}
+	}
+	if len(s.OneOf) > 0 {
+		if problems := s.validateOneOf(path, val); len(problems) > 0 {
+			return problems
+		}
+	}
+
+	var problems []string
+	switch val := val.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%smust contain %q", describePath(path), name))
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			problems = append(problems, s.validateProperty(path, key, val[key])...)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(val) < *s.MinItems {
+			problems = append(problems, fmt.Sprintf("%smust contain at least %s", describePath(path), plural(*s.MinItems, "item", "items")))
+		}
+		for i, elem := range val {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if s.UniqueItems && containsValue(val[:i], elem) {
+				problems = append(problems, fmt.Sprintf("%s%s is specified more than once", describePath(elemPath), describeValue(elem)))
+				continue
+			}
+			if s.Items != nil {
+				problems = append(problems, s.Items.validate(elemPath, elem)...)
+			}
+		}
+	case json.Number:
+		if s.Minimum != nil {
+			if minimum, _ := s.Minimum.Float64(); mustFloat64(val) < minimum {
+				problems = append(problems, fmt.Sprintf("%smust be at least %s, was %s", describePath(path), *s.Minimum, val))
+			}
+		}
+	case string:
+		if s.MinLength != nil && len([]rune(val)) < *s.MinLength {
+			problems = append(problems, fmt.Sprintf("%smust contain at least %s, was %q", describePath(path), plural(*s.MinLength, "character", "characters"), val))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			problems = append(problems, fmt.Sprintf("%smust match the pattern %s, was %q", describePath(path), s.Pattern, val))
+		}
+	}
+	return problems
+}
+
+// validateKey validates a key of an object against the "propertyNames" schema.
+func (s *schema) validateKey(key string) []string {
+	s = s.resolved()
+	if s.PropertyNames == nil {
+		return nil
+	}
+	var problems []string
+	for _, problem := range s.PropertyNames.validate("", key) {
+		problems = append(problems, "key "+problem)
+	}
+	return problems
+}
+
+// validateProperty validates the key and value of a single property of an object.
+func (s *schema) validateProperty(path, key string, val interface{}) []string {
+	problems := s.validateKey(key)
+	if propSchema := s.propertySchema(key); propSchema != nil {
+		problems = append(problems, propSchema.validate(fmt.Sprintf("%s[%q]", path, key), val)...)
+	}
+	return problems
+}
+
+// propertySchema returns the schema for the value of the property with the provided key, or nil if the value of the
+// property is unconstrained.
+func (s *schema) propertySchema(key string) *schema {
+	s = s.resolved()
+	if propSchema, ok := s.Properties[key]; ok {
+		return propSchema
+	}
+	return s.AdditionalProperties
+}
+
+func (s *schema) validateOneOf(path string, val interface{}) []string {
+	var matched int
+	var types schemaTypes
+	var typeMatches [][]string
+	for _, option := range s.OneOf {
+		problems := option.validate(path, val)
+		if len(problems) == 0 {
+			matched++
+			continue
+		}
+		optionType := option.resolved().Type
+		types = append(types, optionType...)
+		if optionType.matches(val) {
+			typeMatches = append(typeMatches, problems)
+		}
+	}
+	switch {
+	case matched == 1:
+		return nil
+	case matched > 1:
+		return []string{describePath(path) + "matches more than one of the allowed forms"}
+	case len(typeMatches) == 1:
+		// the value has the type of exactly one of the options, so report why it does not satisfy that option
+		return typeMatches[0]
+	default:
+		return []string{fmt.Sprintf("%smust be %s, was %s", describePath(path), types, describeValue(val))}
+	}
+}
+
+// schemaTypes is the value of the "type" keyword, which is either a single type or an array of types.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*t = multiple
+	return nil
+}
+
+func (t schemaTypes) String() string {
+	var names []string
+	for _, typ := range t {
+		switch typ {
+		case "array", "integer", "object":
+			names = append(names, "an "+typ)
+		case "null":
+			names = append(names, typ)
+		default:
+			names = append(names, "a "+typ)
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+func (t schemaTypes) matches(val interface{}) bool {
+	for _, typ := range t {
+		switch val := val.(type) {
+		case nil:
+			if typ == "null" {
+				return true
+			}
+		case bool:
+			if typ == "boolean" {
+				return true
+			}
+		case string:
+			if typ == "string" {
+				return true
+			}
+		case json.Number:
+			if typ == "number" {
+				return true
+			}
+			if _, err := val.Int64(); err == nil && typ == "integer" {
+				return true
+			}
+		case []interface{}:
+			if typ == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if typ == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + ": "
+}
+
+func describeValue(val interface{}) string {
+	valBytes, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+	return string(valBytes)
+}
+
+func containsValue(vals []interface{}, val interface{}) bool {
+	for _, curr := range vals {
+		if reflect.DeepEqual(normalizeValue(curr), normalizeValue(val)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeValue converts numbers to float64 so that equal numbers with different representations (such as 1 and
+// 1.0) compare as equal.
+func normalizeValue(val interface{}) interface{} {
+	if num, ok := val.(json.Number); ok {
+		return mustFloat64(num)
+	}
+	return val
+}
+
+func mustFloat64(num json.Number) float64 {
+	f, _ := num.Float64()
+	return f
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	s := mustParseSchema([]byte(`{
+		"type": "object",
+		"required": ["mode"],
+		"properties": {
+			"mode": {"enum": ["exact", "suffix"]},
+			"args": {
+				"type": "array",
+				"items": {
+					"oneOf": [
+						{"type": "integer", "minimum": 0},
+						{"type": "string", "pattern": "^[0-9]+\\+$"}
+					]
+				}
+			}
+		},
+		"additionalProperties": false
+	}`))
+
+	for _, tc := range []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input: `{"mode": "exact", "args": [0, "1+"]}`,
+		},
+		{
+			input: `{"args": [-1, "x", true], "other": 1}`,
+			expected: []string{
+				`must contain "mode"`,
+				`["args"][0]: must be at least 0, was -1`,
+				`["args"][1]: must match the pattern ^[0-9]+\+$, was "x"`,
+				`["args"][2]: must be an integer or a string, was true`,
+				`["other"]: is not allowed`,
+			},
+		},
+		{
+			input: `{"mode": "regex"}`,
+			expected: []string{
+				`["mode"]: must be one of "exact", "suffix", was "regex"`,
+			},
+		},
+	} {
+		val, err := decodeJSONValue([]byte(tc.input))
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, s.validate("", val), tc.input)
+	}
+}
+
+func TestConfigSchemaAcceptsDefaultConfig(t *testing.T) {
+	cfgJSON, err := json.Marshal(defaultCfg)
+	require.NoError(t, err)
+	val, err := decodeJSONValue(cfgJSON)
+	require.NoError(t, err)
+	assert.Empty(t, configSchema.validate("", val))
+}
@@ -0,0 +1,154 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/types"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// anyParams is the parameter pattern that matches any number of parameters.
+	anyParams = "..."
+	// anyType is the parameter pattern that matches a single parameter of any type.
+	anyType = "_"
+	// outParamPrefix is the prefix of the parameter patterns that match output parameters.
+	outParamPrefix = "&"
+)
+
+// SignatureRule specifies the output parameters of the functions and methods whose signature matches a pattern, which
+// makes it possible to check decode-style functions whose names vary.
+type SignatureRule struct {
+	// Packages are the patterns of the import paths of the packages whose functions the rule applies to.
+	Packages []string `json:"packages"`
+	// Params are the patterns of the parameters of the matched functions. Each pattern is either the type of the
+	// parameter (such as "[]byte" or "encoding/json.RawMessage", where "any" and "interface{}" are equivalent), "_",
+	// which matches a parameter of any type, or "...", which matches any number of parameters and may be specified at
+	// most once. The patterns of the output parameters are prefixed by "&".
+	Params []string `json:"params"`
+	// Results are the types of the results of the matched functions. If it is nil, the results are not constrained.
+	Results []string `json:"results,omitempty"`
+	// Severity is the severity of the errors reported by the rule.
+	Severity Severity `json:"severity,omitempty"`
+}
+
+// validate returns an error if the parameter patterns of the rule are not valid.
+func (r *SignatureRule) validate() error {
+	var numAnyParams, numOutParams int
+	for _, param := range r.Params {
+		if strings.HasPrefix(param, outParamPrefix) {
+			numOutParams++
+			param = strings.TrimPrefix(param, outParamPrefix)
+		}
+		if param == anyParams {
+			numAnyParams++
+		}
+	}
+	if numAnyParams > 1 {
+		return errors.Errorf("%q may be specified at most once in params", anyParams)
+	}
+	if numOutParams == 0 {
+		return errors.Errorf("params must contain at least one output parameter prefixed by %q", outParamPrefix)
+	}
+	for _, param := range r.Params {
+		if param == outParamPrefix+anyParams {
+			return errors.Errorf("%q cannot be an output parameter", anyParams)
+		}
+	}
+	return nil
+}
+
+// outParams returns the indices of the output parameters of the provided function if the rule matches it.
+func (r *SignatureRule) outParams(fn *types.Func) ([]int, bool) {
+	if fn.Pkg() == nil || !matchesAnyPackagePattern(r.Packages, canonicalKey(fn.Pkg().Path())) {
+		return nil, false
+	}
+	sig := fn.Type().(*types.Signature)
+	if r.Results != nil && !matchesTypes(r.Results, sig.Results()) {
+		return nil, false
+	}
+
+	params := sig.Params()
+	numBefore, numAfter := len(r.Params), 0
+	for i, param := range r.Params {
+		if param == anyParams {
+			numBefore, numAfter = i, len(r.Params)-i-1
+		}
+	}
+	if (numBefore == len(r.Params) && params.Len() != len(r.Params)) || params.Len() < numBefore+numAfter {
+		return nil, false
+	}
+
+	var outParams []int
+	for i, param := range r.Params {
+		if param == anyParams {
+			continue
+		}
+		paramIdx := i
+		if i > numBefore {
+			// the pattern follows "..." so it is matched against the parameters at the end
+			paramIdx = params.Len() - (len(r.Params) - i)
+		}
+		isOut := strings.HasPrefix(param, outParamPrefix)
+		if !matchesType(strings.TrimPrefix(param, outParamPrefix), params.At(paramIdx).Type()) {
+			return nil, false
+		}
+		if isOut {
+			outParams = append(outParams, paramIdx)
+		}
+	}
+	return outParams, true
+}
+
+// matchesTypes returns true if the provided tuple consists of the types matched by the provided patterns.
+func matchesTypes(patterns []string, tuple *types.Tuple) bool {
+	if len(patterns) != tuple.Len() {
+		return false
+	}
+	for i, pattern := range patterns {
+		if !matchesType(pattern, tuple.At(i).Type()) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesType returns true if the provided type pattern matches the provided type.
+func matchesType(pattern string, typ types.Type) bool {
+	if pattern == anyType {
+		return true
+	}
+	return normalizeTypeString(pattern) == normalizeTypeString(types.TypeString(types.Unalias(typ), nil))
+}
+
+// normalizeTypeString returns the provided type string with every "any" replaced by "interface{}".
+func normalizeTypeString(typ string) string {
+	var b strings.Builder
+	for i := 0; i < len(typ); {
+		if !isTypeNameByte(typ[i]) {
+			b.WriteByte(typ[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(typ) && isTypeNameByte(typ[j]) {
+			j++
+		}
+		if word := typ[i:j]; word == "any" {
+			b.WriteString("interface{}")
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// isTypeNameByte returns true if the provided byte can be part of a package-qualified type name.
+func isTypeNameByte(c byte) bool {
+	return c == '_' || c == '/' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,74 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureRuleOutParams(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		func ReadConfig(path string, out interface{}) error { return nil }
+		func DecodeAny(data []byte, opts int, out any) error { return nil }
+		func Parse(out interface{}) {}
+		func Lookup(key string, out *string) error { return nil }
+
+		func main() {}
+		`)
+	pkgPath := pkgs[0].PkgPath
+	lookup := func(name string) *types.Func {
+		return pkgs[0].Types.Scope().Lookup(name).(*types.Func)
+	}
+
+	for i, tc := range []struct {
+		rule *SignatureRule
+		fn   string
+		want []int
+		ok   bool
+	}{
+		{&SignatureRule{Packages: []string{pkgPath}, Params: []string{"...", "&interface{}"}, Results: []string{"error"}}, "ReadConfig", []int{1}, true},
+		{&SignatureRule{Packages: []string{pkgPath}, Params: []string{"...", "&interface{}"}, Results: []string{"error"}}, "DecodeAny", []int{2}, true},
+		{&SignatureRule{Packages: []string{pkgPath}, Params: []string{"...", "&any"}, Results: []string{"error"}}, "Parse", nil, false},
+		{&SignatureRule{Packages: []string{pkgPath}, Params: []string{"...", "&any"}}, "Parse", []int{0}, true},
+		{&SignatureRule{Packages: []string{pkgPath}, Params: []string{"[]byte", "&_", "..."}}, "DecodeAny", []int{1}, true},
+		{&SignatureRule{Packages: []string{pkgPath}, Params: []string{"&_"}}, "ReadConfig", nil, false},
+		{&SignatureRule{Packages: []string{pkgPath}, Params: []string{"string", "&*string"}}, "Lookup", []int{1}, true},
+		{&SignatureRule{Packages: []string{"example.com/..."}, Params: []string{"...", "&interface{}"}}, "ReadConfig", nil, false},
+	} {
+		got, ok := tc.rule.outParams(lookup(tc.fn))
+		assert.Equal(t, tc.ok, ok, "Case %d", i)
+		assert.Equal(t, tc.want, got, "Case %d", i)
+	}
+}
+
+func TestSignatureRuleValidate(t *testing.T) {
+	for i, tc := range []struct {
+		params []string
+		want   string
+	}{
+		{[]string{"...", "&interface{}"}, ""},
+		{[]string{"...", "&_", "..."}, `"..." may be specified at most once in params`},
+		{[]string{"...", "interface{}"}, `params must contain at least one output parameter prefixed by "&"`},
+		{[]string{"&...", "&_"}, `"..." cannot be an output parameter`},
+	} {
+		err := (&SignatureRule{Packages: []string{"..."}, Params: tc.params}).validate()
+		if tc.want == "" {
+			assert.NoError(t, err, "Case %d", i)
+		} else {
+			assert.EqualError(t, err, tc.want, "Case %d", i)
+		}
+	}
+}
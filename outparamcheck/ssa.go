@@ -0,0 +1,137 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+const (
+	// ModeAST decides whether the arguments of calls are addresses by inspecting the syntax of the arguments and of the
+	// declarations of the variables that are passed.
+	ModeAST = "ast"
+	// ModeSSA decides whether the arguments of calls are addresses by tracking the values that reach the calls through
+	// the SSA form of the checked packages, which follows reassignments and conditional assignments.
+	ModeSSA = "ssa"
+)
+
+// ssaCalls are the calls of the SSA form of the checked packages keyed by the position of the opening parenthesis of
+// the call expressions.
+type ssaCalls map[token.Pos]*ssa.CallCommon
+
+// validateMode returns an error if the provided analysis mode is not supported.
+func validateMode(mode string) error {
+	switch mode {
+	case "", ModeAST, ModeSSA:
+		return nil
+	default:
+		return errors.Errorf("invalid mode %q: must be %q or %q", mode, ModeAST, ModeSSA)
+	}
+}
+
+// buildSSACalls builds the SSA form of the provided packages and returns their calls. Only the functions of the
+// provided packages are built and walked, so the size of the program that the packages depend on does not matter.
+func buildSSACalls(pkgs []*packages.Package) ssaCalls {
+	prog, ssaPkgs := ssautil.Packages(pkgs, 0)
+
+	calls := make(ssaCalls)
+	for _, ssaPkg := range ssaPkgs {
+		if ssaPkg == nil {
+			// the SSA form of packages with type errors is not built
+			continue
+		}
+		ssaPkg.Build()
+		for _, member := range ssaPkg.Members {
+			switch member := member.(type) {
+			case *ssa.Function:
+				calls.addFunc(member)
+			case *ssa.Type:
+				// methods are not members of the package, but of the named types that declare them
+				named, ok := member.Type().(*types.Named)
+				if !ok || named.Obj() != member.Object() {
+					continue
+				}
+				for i := 0; i < named.NumMethods(); i++ {
+					if fn := prog.FuncValue(named.Method(i)); fn != nil {
+						calls.addFunc(fn)
+					}
+				}
+			}
+		}
+	}
+	return calls
+}
+
+// addFunc adds the calls of the provided function and of the anonymous functions that it contains.
+func (c ssaCalls) addFunc(fn *ssa.Function) {
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			if call, ok := instr.(ssa.CallInstruction); ok && call.Common().Pos().IsValid() {
+				c[call.Common().Pos()] = call.Common()
+			}
+		}
+	}
+	for _, anon := range fn.AnonFuncs {
+		c.addFunc(anon)
+	}
+}
+
+// ssaArg returns the value of the argument with the provided index of the provided call in the SSA form of the
+// checked package. It returns false if the value is unknown, such as for the variadic arguments of a call, which are
+// packed into a slice.
+func (c ssaCalls) ssaArg(call *ast.CallExpr, i int) (ssa.Value, bool) {
+	common, ok := c[call.Lparen]
+	if !ok {
+		return nil, false
+	}
+	// the receiver of a static method call is passed as the first argument
+	offset := len(common.Args) - len(call.Args)
+	if sig := common.Signature(); sig.Variadic() && !call.Ellipsis.IsValid() {
+		if i >= sig.Params().Len()-1 {
+			return nil, false
+		}
+		offset = len(common.Args) - sig.Params().Len()
+	}
+	if offset < 0 || offset+i >= len(common.Args) {
+		return nil, false
+	}
+	return common.Args[offset+i], true
+}
+
+// isSSAAddr returns true if the provided value is an address or nil in every execution, including when it is converted
+// to an interface.
+func isSSAAddr(value ssa.Value, visiting map[*ssa.Phi]bool) bool {
+	switch value := value.(type) {
+	case *ssa.Const:
+		return value.IsNil() || isPointer(value.Type())
+	case *ssa.MakeInterface:
+		return isSSAAddr(value.X, visiting)
+	case *ssa.ChangeInterface:
+		return isSSAAddr(value.X, visiting)
+	case *ssa.ChangeType:
+		return isSSAAddr(value.X, visiting)
+	case *ssa.Phi:
+		if visiting[value] {
+			// the values that reach the cycle are checked by the other edges
+			return true
+		}
+		visiting[value] = true
+		for _, edge := range value.Edges {
+			if !isSSAAddr(edge, visiting) {
+				return false
+			}
+		}
+		return true
+	default:
+		return isPointer(value.Type())
+	}
+}
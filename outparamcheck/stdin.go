@@ -0,0 +1,55 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// stdinFileName is the name of the file that the source read from standard input is checked as if the package path is
+// the directory of a package.
+const stdinFileName = "stdin.go"
+
+// stdinSource is the source of a Go file that is read from standard input, such as an unsaved editor buffer.
+type stdinSource struct {
+	// filename is the absolute path of the file that the source is checked as.
+	filename string
+	// pattern is the pattern of the package that contains the file.
+	pattern string
+	// overlay replaces the contents of the file with the source.
+	overlay map[string][]byte
+}
+
+// readStdinSource reads the source of a Go file from standard input, which is checked as the file with the provided
+// path or, if the path is the directory of a package, as a new file of the package named stdinFileName. The file is
+// type-checked along with the other files of its package.
+func readStdinSource(packagePath string) (stdinSource, error) {
+	if packagePath == "" {
+		return stdinSource{}, errors.Errorf("the package path of the source read from standard input must be specified")
+	}
+	absPath, err := filepath.Abs(packagePath)
+	if err != nil {
+		return stdinSource{}, errors.Wrapf(err, "failed to determine absolute path of %s", packagePath)
+	}
+	filename := absPath
+	if info, err := os.Stat(absPath); err == nil && info.IsDir() {
+		filename = filepath.Join(absPath, stdinFileName)
+	} else if !isGoFileArg(absPath) {
+		return stdinSource{}, errors.Errorf("package path %s must be a directory or a Go file", packagePath)
+	}
+	src, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return stdinSource{}, errors.Wrapf(err, "failed to read standard input")
+	}
+	return stdinSource{
+		filename: filename,
+		pattern:  filepath.Dir(filename),
+		overlay:  map[string][]byte{filename: src},
+	}, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestReadStdinSource(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "a.go"), []byte(`package lib
+
+func A() {}
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "b.go"), []byte(`package lib
+
+type config struct{}
+
+func newConfig() *config { return nil }
+`), 0644))
+
+	origStdin := stdin
+	defer func() {
+		stdin = origStdin
+	}()
+	// the unsaved contents of a.go, which calls a function declared in b.go
+	const source = `package lib
+
+import (
+	"encoding/json"
+)
+
+func A() {
+	var x interface{}
+	_ = json.Unmarshal(nil, x)
+	_ = json.Unmarshal(nil, newConfig())
+}
+`
+
+	for _, tc := range []struct {
+		name         string
+		packagePath  string
+		wantFilename string
+	}{
+		{"file", path.Join(tmpDir, "a.go"), "a.go"},
+		{"directory", tmpDir, stdinFileName},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stdin = strings.NewReader(strings.Replace(source, "func A", "func "+strings.ToUpper(tc.name), 1))
+			src, err := readStdinSource(tc.packagePath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantFilename, filepath.Base(src.filename))
+
+			pkgs, err := load([]string{src.pattern}, nil, nil, testsAll, src.overlay)
+			require.NoError(t, err)
+			cfg := argsConfig(map[string][]int{
+				"encoding/json.Unmarshal": {1},
+			})
+			errs := goFiles{src.filename: true}.filter(runWithConfigs(pkgs, func(*packages.Package) Config {
+				return cfg
+			}, nil, analysis{overlay: src.overlay}))
+			require.Len(t, errs, 1)
+			assert.Equal(t, src.filename, errs[0].Pos.Filename)
+			assert.Equal(t, 9, errs[0].Pos.Line)
+			assert.Equal(t, "_ = json.Unmarshal(nil, x)", errs[0].Line)
+		})
+	}
+
+	_, err = readStdinSource("")
+	assert.EqualError(t, err, "the package path of the source read from standard input must be specified")
+	_, err = readStdinSource(path.Join(tmpDir, "a.txt"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// expiryDateLayout is the layout of the expiry dates of suppressions.
+const expiryDateLayout = "2006-01-02"
+
+// now returns the current time, which determines whether suppressions have expired.
+var now = time.Now
+
+// suppressionFile is a file of suppressions, each of which suppresses the errors that it matches until it expires.
+type suppressionFile struct {
+	Suppressions []suppression `json:"suppressions"`
+}
+
+// suppression suppresses the errors in the files that match File. The errors can be narrowed down further by the name of
+// the called function and by the source line on which they are reported. Every suppression must specify the owner that
+// is responsible for it and the reason for it, and may specify the date on which it expires, after which the errors
+// that it matches are reported again.
+type suppression struct {
+	// File is a glob pattern that is matched against the slash-separated path of the file relative to the working
+	// directory.
+	File string `json:"file"`
+	// Method is the name of the called function or method. Errors for any function are matched if it is empty.
+	Method string `json:"method,omitempty"`
+	// Line is the trimmed source line on which the error is reported. Errors on any line are matched if it is empty.
+	Line   string `json:"line,omitempty"`
+	Owner  string `json:"owner"`
+	Reason string `json:"reason"`
+	// Expires is the date (in the form YYYY-MM-DD) on which the suppression expires. The suppression does not expire if
+	// it is empty.
+	Expires string `json:"expires,omitempty"`
+
+	// expires is the parsed value of Expires, which is the zero time if the suppression does not expire.
+	expires time.Time
+}
+
+func readSuppressions(suppressionsPath string) ([]suppression, error) {
+	suppressionsBytes, err := ioutil.ReadFile(suppressionsPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read suppressions %s", suppressionsPath)
+	}
+	var f suppressionFile
+	if err := json.Unmarshal([]byte(stripJSONC(string(suppressionsBytes))), &f); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse suppressions %s", suppressionsPath)
+	}
+	var problems []string
+	for i := range f.Suppressions {
+		s := &f.Suppressions[i]
+		for _, problem := range s.validate() {
+			problems = append(problems, fmt.Sprintf("suppression %d: %s", i, problem))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, errors.Errorf("invalid suppressions %s:\n\t%s", suppressionsPath, strings.Join(problems, "\n\t"))
+	}
+	return f.Suppressions, nil
+}
+
+// validate returns the problems of the suppression and parses its expiry date.
+func (s *suppression) validate() []string {
+	var problems []string
+	if s.File == "" {
+		problems = append(problems, `"file" must be specified`)
+	} else if _, err := path.Match(s.File, ""); err != nil {
+		problems = append(problems, fmt.Sprintf(`"file" is not a valid glob pattern: %v`, err))
+	}
+	if strings.TrimSpace(s.Owner) == "" {
+		problems = append(problems, `"owner" must be specified`)
+	}
+	if strings.TrimSpace(s.Reason) == "" {
+		problems = append(problems, `"reason" must be specified`)
+	}
+	if s.Expires != "" {
+		expires, err := time.ParseInLocation(expiryDateLayout, s.Expires, time.Local)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf(`"expires" must be a date of the form YYYY-MM-DD, was %q`, s.Expires))
+		}
+		s.expires = expires
+	}
+	return problems
+}
+
+// expired returns true if the suppression has expired at the provided time. A suppression expires at the start of the
+// day of its expiry date.
+func (s suppression) expired(t time.Time) bool {
+	return !s.expires.IsZero() && !t.Before(s.expires)
+}
+
+// matches returns true if the suppression matches the provided error, whose file has the provided slash-separated path
+// relative to the working directory.
+func (s suppression) matches(err OutParamError, relPath string) bool {
+	if matched, _ := path.Match(s.File, relPath); !matched {
+		return false
+	}
+	return (s.Method == "" || s.Method == err.Method) && (s.Line == "" || s.Line == err.Line)
+}
+
+// filterSuppressed returns the provided errors that are not matched by any of the provided suppressions that have not
+// expired.
+func filterSuppressed(errs []OutParamError, suppressions []suppression) []OutParamError {
+	t := now()
+	wd, wdErr := os.Getwd()
+	var filtered []OutParamError
+	for _, err := range errs {
+		relPath := err.Pos.Filename
+		if wdErr == nil {
+			if rel, relErr := filepath.Rel(wd, relPath); relErr == nil {
+				relPath = rel
+			}
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		suppressed := false
+		for _, s := range suppressions {
+			if !s.expired(t) && s.matches(err, relPath) {
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			filtered = append(filtered, err)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,88 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterSuppressed(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	suppressionsPath := path.Join(tmpDir, "suppressions.json")
+	require.NoError(t, ioutil.WriteFile(suppressionsPath, []byte(`{
+		"suppressions": [
+			// the legacy package is being rewritten
+			{"file": "legacy/*.go", "owner": "platform", "reason": "rewrite in progress", "expires": "2026-06-01"},
+			{"file": "api/client.go", "method": "Decode", "owner": "api", "reason": "decodes into a map"},
+			{"file": "api/server.go", "line": "json.Unmarshal(body, req)", "owner": "api", "reason": "req is a pointer"}
+		]
+	}`), 0644))
+	suppressions, err := readSuppressions(suppressionsPath)
+	require.NoError(t, err)
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	newErr := func(file, method, line string) OutParamError {
+		return OutParamError{
+			Pos:    token.Position{Filename: filepath.Join(wd, filepath.FromSlash(file)), Line: 1},
+			Line:   line,
+			Method: method,
+		}
+	}
+	errs := []OutParamError{
+		newErr("legacy/config.go", "Unmarshal", "json.Unmarshal(data, cfg)"),
+		newErr("api/client.go", "Decode", "dec.Decode(m)"),
+		newErr("api/client.go", "Unmarshal", "json.Unmarshal(data, m)"),
+		newErr("api/server.go", "Unmarshal", "json.Unmarshal(body, req)"),
+		newErr("api/server.go", "Unmarshal", "json.Unmarshal(body, resp)"),
+	}
+
+	defer func(orig func() time.Time) {
+		now = orig
+	}(now)
+	now = func() time.Time {
+		return time.Date(2026, 5, 31, 23, 0, 0, 0, time.Local)
+	}
+	assert.Equal(t, []OutParamError{errs[2], errs[4]}, filterSuppressed(errs, suppressions))
+
+	// the suppression of the legacy package has expired
+	now = func() time.Time {
+		return time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local)
+	}
+	assert.Equal(t, []OutParamError{errs[0], errs[2], errs[4]}, filterSuppressed(errs, suppressions))
+}
+
+func TestReadSuppressionsErrors(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	suppressionsPath := path.Join(tmpDir, "suppressions.json")
+	require.NoError(t, ioutil.WriteFile(suppressionsPath, []byte(`{
+		"suppressions": [
+			{"file": "legacy/*.go", "owner": "platform", "reason": "rewrite in progress", "expires": "June 1"},
+			{"file": "api/[.go", "reason": " "}
+		]
+	}`), 0644))
+	_, err = readSuppressions(suppressionsPath)
+	assert.EqualError(t, err, "invalid suppressions "+suppressionsPath+":\n"+
+		"\tsuppression 0: \"expires\" must be a date of the form YYYY-MM-DD, was \"June 1\"\n"+
+		"\tsuppression 1: \"file\" is not a valid glob pattern: syntax error in pattern\n"+
+		"\tsuppression 1: \"owner\" must be specified\n"+
+		"\tsuppression 1: \"reason\" must be specified")
+}
@@ -0,0 +1,76 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+)
+
+// checkUncheckedErrors reports the calls of the provided file to functions with output parameters whose error result
+// is discarded, either because the call is a statement of its own (including go and defer statements) or because the
+// error is assigned to the blank identifier. A decode function that fails may leave its output parameters partially
+// populated, so its error should be checked.
+func (v *visitor) checkUncheckedErrors(file *ast.File) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.ExprStmt:
+			if call, ok := ast.Unparen(node.X).(*ast.CallExpr); ok {
+				v.checkUncheckedError(call)
+			}
+		case *ast.GoStmt:
+			v.checkUncheckedError(node.Call)
+		case *ast.DeferStmt:
+			v.checkUncheckedError(node.Call)
+		case *ast.AssignStmt:
+			if len(node.Rhs) != 1 {
+				break
+			}
+			call, ok := ast.Unparen(node.Rhs[0]).(*ast.CallExpr)
+			if !ok {
+				break
+			}
+			if i := v.errorResultIndex(call); i != -1 && i < len(node.Lhs) {
+				if ident, ok := node.Lhs[i].(*ast.Ident); ok && ident.Name == "_" {
+					v.checkUncheckedError(call)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// checkUncheckedError reports the provided call, whose error result is discarded, if it calls a function with output
+// parameters.
+func (v *visitor) checkUncheckedError(call *ast.CallExpr) {
+	if v.errorResultIndex(call) == -1 {
+		return
+	}
+	method, outArgs := v.callOutArgs(call)
+	if len(outArgs) == 0 {
+		return
+	}
+	v.subcheckErrorAt(call.Pos(), CheckUncheckedError, SeverityError,
+		fmt.Sprintf("error returned by '%s' is not checked", method))
+}
+
+// errorResultIndex returns the index of the last result of the function called by the provided call if the result is
+// an error and -1 otherwise.
+func (v *visitor) errorResultIndex(call *ast.CallExpr) int {
+	typ := v.pkg.TypesInfo.TypeOf(call.Fun)
+	if typ == nil {
+		return -1
+	}
+	sig, ok := typ.Underlying().(*types.Signature)
+	if !ok || sig.Results().Len() == 0 {
+		return -1
+	}
+	last := sig.Results().Len() - 1
+	if !types.Identical(sig.Results().At(last).Type(), types.Universe.Lookup("error").Type()) {
+		return -1
+	}
+	return last
+}
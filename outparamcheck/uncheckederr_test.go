@@ -0,0 +1,61 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckUncheckedErrors(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+			"fmt"
+			"strings"
+		)
+
+		func main() {
+			var x interface{}
+			json.Unmarshal(nil, &x)
+			_ = json.Unmarshal(nil, &x)
+			go json.Unmarshal(nil, &x)
+			defer json.Unmarshal(nil, &x)
+			dec := json.NewDecoder(strings.NewReader(""))
+			dec.Decode(&x)
+			_, _ = fmt.Sscan("1", &x)
+
+			if err := json.Unmarshal(nil, &x); err != nil {
+				panic(err)
+			}
+			err := dec.Decode(&x)
+			_ = err
+			fmt.Println(x)
+			_ = json.Valid(nil)
+		}
+		`)
+	cfg := argsConfig(map[string][]int{
+		"encoding/json.Unmarshal":      {1},
+		"encoding/json.Decoder.Decode": {0},
+		"fmt.Sscan":                    {1},
+	})
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 114, Line: 12, Column: 4}, Line: "json.Unmarshal(nil, &x)", Problem: "error returned by 'Unmarshal' is not checked", Check: CheckUncheckedError},
+		{Pos: token.Position{Offset: 145, Line: 13, Column: 8}, Line: "_ = json.Unmarshal(nil, &x)", Problem: "error returned by 'Unmarshal' is not checked", Check: CheckUncheckedError},
+		{Pos: token.Position{Offset: 175, Line: 14, Column: 7}, Line: "go json.Unmarshal(nil, &x)", Problem: "error returned by 'Unmarshal' is not checked", Check: CheckUncheckedError},
+		{Pos: token.Position{Offset: 208, Line: 15, Column: 10}, Line: "defer json.Unmarshal(nil, &x)", Problem: "error returned by 'Unmarshal' is not checked", Check: CheckUncheckedError},
+		{Pos: token.Position{Offset: 284, Line: 17, Column: 4}, Line: "dec.Decode(&x)", Problem: "error returned by 'Decode' is not checked", Check: CheckUncheckedError},
+		{Pos: token.Position{Offset: 309, Line: 18, Column: 11}, Line: `_, _ = fmt.Sscan("1", &x)`, Problem: "error returned by 'Sscan' is not checked", Check: CheckUncheckedError},
+	}, runChecks(t, pkgs, cfg, CheckUncheckedError))
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"github.com/dustin/go-humanize"
+)
+
+// checkUnreadOutputs reports the output parameters of the calls of the provided file that are passed the address of a
+// local variable that is never referred to after the call, which indicates that the decoded value is never used. A
+// variable that is referred to anywhere within a loop that contains the call is assumed to be read, since the reference
+// may follow the call in the next iteration. Variables that are captured by function literals, variables whose address
+// is taken elsewhere and the named results of functions are not checked.
+func (v *visitor) checkUnreadOutputs(file *ast.File) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			if node.Body != nil {
+				v.checkFuncOutputs(node.Type, node.Body)
+			}
+		case *ast.FuncLit:
+			v.checkFuncOutputs(node.Type, node.Body)
+		}
+		return true
+	})
+}
+
+// checkFuncOutputs reports the unread output parameters of the calls of the function with the provided type and body.
+// The calls within the function literals of the body are checked as part of the function literals.
+func (v *visitor) checkFuncOutputs(typ *ast.FuncType, body *ast.BlockStmt) {
+	if hasLabels(body) {
+		return
+	}
+	vars := v.localVars(typ, body)
+	captured, addressed := v.escapedVars(body)
+	var loops []ast.Node
+	inspectFunc(body, func(node ast.Node) {
+		switch node := node.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			loops = append(loops, node)
+		case *ast.CallExpr:
+			method, outArgs := v.callOutArgs(node)
+			indices := make([]int, 0, len(outArgs))
+			for i := range outArgs {
+				indices = append(indices, i)
+			}
+			sort.Ints(indices)
+			for _, i := range indices {
+				unary, ok := ast.Unparen(node.Args[i]).(*ast.UnaryExpr)
+				if !ok || unary.Op != token.AND {
+					continue
+				}
+				ident, ok := ast.Unparen(unary.X).(*ast.Ident)
+				if !ok {
+					continue
+				}
+				obj, ok := v.pkg.TypesInfo.Uses[ident].(*types.Var)
+				// the address must only be taken by the call, since the variable could be read through another pointer
+				if !ok || !vars[obj] || captured[obj] || addressed[obj] != 1 || v.isReferredToAfter(obj, node, body, loops) {
+					continue
+				}
+				v.report(node.Args[i].Pos(), OutParamError{
+					Method:   method,
+					Rule:     outArgs[i].rule,
+					Argument: i,
+					Severity: SeverityError,
+					Problem: fmt.Sprintf("%s argument of '%s' decodes into %s, which is never read afterwards",
+						humanize.Ordinal(i+1), method, ident.Name),
+					Check: CheckUnreadOutput,
+				})
+			}
+		}
+	})
+}
+
+// isReferredToAfter returns true if the provided variable is referred to after the provided call within the provided
+// function body or anywhere within one of the provided loops that contains the call other than by the call itself.
+func (v *visitor) isReferredToAfter(obj *types.Var, call *ast.CallExpr, body *ast.BlockStmt, loops []ast.Node) bool {
+	from := call.End()
+	for _, loop := range loops {
+		if loop.Pos() <= call.Pos() && call.End() <= loop.End() && loop.Pos() < from {
+			from = loop.Pos()
+		}
+	}
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if found || node == call {
+			return false
+		}
+		if ident, ok := node.(*ast.Ident); ok && ident.Pos() >= from && v.pkg.TypesInfo.Uses[ident] == obj {
+			found = true
+		}
+		return true
+	})
+	return found
+}
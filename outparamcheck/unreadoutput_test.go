@@ -0,0 +1,93 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckUnreadOutputs(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+			"fmt"
+		)
+
+		type Config struct {
+			Name string
+		}
+
+		func unread(data []byte) error {
+			var cfg Config
+			return json.Unmarshal(data, &cfg)
+		}
+
+		func read(data []byte) error {
+			var cfg Config
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				return err
+			}
+			fmt.Println(cfg.Name)
+			return nil
+		}
+
+		func loop(items [][]byte) {
+			var last Config
+			for _, item := range items {
+				fmt.Println(last)
+				_ = json.Unmarshal(item, &last)
+			}
+			for _, item := range items {
+				var cfg Config
+				_ = json.Unmarshal(item, &cfg)
+			}
+		}
+
+		func twice(a, b []byte) {
+			var cfg Config
+			_ = json.Unmarshal(a, &cfg)
+			_ = json.Unmarshal(b, &cfg)
+		}
+
+		func result(data []byte) (cfg Config, err error) {
+			err = json.Unmarshal(data, &cfg)
+			return
+		}
+
+		func captured(data []byte) func() Config {
+			var cfg Config
+			_ = json.Unmarshal(data, &cfg)
+			return func() Config {
+				return cfg
+			}
+		}
+
+		func main() {
+			f := func(data []byte) {
+				var v interface{}
+				_ = json.Unmarshal(data, &v)
+			}
+			f(nil)
+		}
+		`)
+	cfg := argsConfig(map[string][]int{
+		"encoding/json.Unmarshal": {1},
+	})
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 188, Line: 15, Column: 32}, Line: "return json.Unmarshal(data, &cfg)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' decodes into cfg, which is never read afterwards", Check: CheckUnreadOutput},
+		{Pos: token.Position{Offset: 592, Line: 35, Column: 30}, Line: "_ = json.Unmarshal(item, &cfg)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' decodes into cfg, which is never read afterwards", Check: CheckUnreadOutput},
+		{Pos: token.Position{Offset: 1068, Line: 61, Column: 30}, Line: "_ = json.Unmarshal(data, &v)", Method: "Unmarshal", Rule: "encoding/json.Unmarshal", Argument: 1, Problem: "2nd argument of 'Unmarshal' decodes into v, which is never read afterwards", Check: CheckUnreadOutput},
+	}, runChecks(t, pkgs, cfg, CheckUnreadOutput))
+}
@@ -0,0 +1,283 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// checkUnusedAssignments reports the values that are assigned to the local variables and parameters of the functions
+// of the provided file but are never read, either because the variable is assigned again before it is read or because
+// the function returns. Only the statements that follow an assignment in the same statement list are considered, so
+// the analysis is conservative: a statement that refers to the variable in any other way, including an assignment in a
+// nested block, is assumed to read it, as are jumps. Variables whose address is taken and variables that are captured
+// by function literals are not checked.
+func (v *visitor) checkUnusedAssignments(file *ast.File) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			if node.Body != nil {
+				v.checkFuncAssignments(node.Type, node.Body)
+			}
+		case *ast.FuncLit:
+			v.checkFuncAssignments(node.Type, node.Body)
+		}
+		return true
+	})
+}
+
+// checkFuncAssignments reports the unused assignments of the function with the provided type and body. The bodies of
+// the function literals within the body are not checked, since they are functions of their own.
+func (v *visitor) checkFuncAssignments(typ *ast.FuncType, body *ast.BlockStmt) {
+	if hasLabels(body) {
+		return
+	}
+	vars := v.uncapturedVars(typ, body)
+	if len(vars) == 0 {
+		return
+	}
+	inspectFunc(body, func(node ast.Node) {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			v.checkStmtAssignments(node.List, vars, node == body)
+		case *ast.CaseClause:
+			v.checkStmtAssignments(node.Body, vars, false)
+		case *ast.CommClause:
+			v.checkStmtAssignments(node.Body, vars, false)
+		}
+	})
+}
+
+// checkStmtAssignments reports the assignments of the provided statements whose values are never read by the
+// statements that follow them. If last is true, the statements are the body of the function, after which the function
+// returns.
+func (v *visitor) checkStmtAssignments(stmts []ast.Stmt, vars map[*types.Var]bool, last bool) {
+	for i, stmt := range stmts {
+		for _, ident := range v.assignedIdents(stmt) {
+			obj, ok := v.pkg.TypesInfo.ObjectOf(ident).(*types.Var)
+			if !ok || !vars[obj] {
+				continue
+			}
+			if v.isUnread(obj, stmts[i+1:], last) {
+				v.subcheckErrorAt(ident.Pos(), CheckUnusedAssignment, SeverityError,
+					fmt.Sprintf("value assigned to %s is never used", ident.Name))
+			}
+		}
+	}
+}
+
+// isUnread returns true if the value of the provided variable is not read by the provided statements, which follow
+// its assignment, because they assign the variable before reading it or return from the function. If last is true,
+// the function returns after the statements.
+func (v *visitor) isUnread(obj *types.Var, stmts []ast.Stmt, last bool) bool {
+	for _, stmt := range stmts {
+		if v.overwrites(stmt, obj) {
+			return true
+		}
+		if v.refersTo(stmt, obj) {
+			return false
+		}
+		switch stmt.(type) {
+		case *ast.ReturnStmt:
+			return true
+		case *ast.BranchStmt:
+			return false
+		}
+	}
+	return last
+}
+
+// assignedIdents returns the identifiers of the variables to which the provided statement assigns values, which does
+// not include variables that are declared without a value or assigned nil.
+func (v *visitor) assignedIdents(stmt ast.Stmt) []*ast.Ident {
+	var lhs, rhs []ast.Expr
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		lhs, rhs = stmt.Lhs, stmt.Rhs
+	case *ast.DeclStmt:
+		genDecl, ok := stmt.Decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			return nil
+		}
+		var idents []*ast.Ident
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, name := range valueSpec.Names {
+				if len(valueSpec.Values) > 0 && !isNilValue(valueSpec.Values, len(valueSpec.Names), i) {
+					idents = append(idents, name)
+				}
+			}
+		}
+		return idents
+	default:
+		return nil
+	}
+	var idents []*ast.Ident
+	for i, expr := range lhs {
+		if ident, ok := expr.(*ast.Ident); ok && ident.Name != "_" && !isNilValue(rhs, len(lhs), i) {
+			idents = append(idents, ident)
+		}
+	}
+	return idents
+}
+
+// isNilValue returns true if the value with the provided index of the provided values, which are assigned to the
+// provided number of variables, is the literal nil.
+func isNilValue(values []ast.Expr, numVars, i int) bool {
+	if len(values) != numVars {
+		return false
+	}
+	ident, ok := ast.Unparen(values[i]).(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
+
+// overwrites returns true if the provided statement assigns a value to the provided variable without reading it.
+func (v *visitor) overwrites(stmt ast.Stmt, obj *types.Var) bool {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN && assign.Tok != token.DEFINE {
+		return false
+	}
+	assigned := false
+	for _, expr := range assign.Lhs {
+		if ident, ok := expr.(*ast.Ident); ok && v.pkg.TypesInfo.ObjectOf(ident) == obj {
+			assigned = true
+		} else if v.refersTo(expr, obj) {
+			return false
+		}
+	}
+	if !assigned {
+		return false
+	}
+	for _, expr := range assign.Rhs {
+		if v.refersTo(expr, obj) {
+			return false
+		}
+	}
+	return true
+}
+
+// refersTo returns true if the provided node refers to the provided variable.
+func (v *visitor) refersTo(node ast.Node, obj *types.Var) bool {
+	found := false
+	ast.Inspect(node, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && v.pkg.TypesInfo.ObjectOf(ident) == obj {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// uncapturedVars returns the parameters and local variables of the function with the provided type and body other
+// than its named results, the variables that are captured by the function literals within the body and the variables
+// whose address is taken, which may be read through a pointer.
+func (v *visitor) uncapturedVars(typ *ast.FuncType, body *ast.BlockStmt) map[*types.Var]bool {
+	vars := v.localVars(typ, body)
+	captured, addressed := v.escapedVars(body)
+	for obj := range vars {
+		if captured[obj] || addressed[obj] > 0 {
+			delete(vars, obj)
+		}
+	}
+	return vars
+}
+
+// localVars returns the parameters and local variables of the function with the provided type and body other than its
+// named results. The variables that are declared within the function literals of the body are not included.
+func (v *visitor) localVars(typ *ast.FuncType, body *ast.BlockStmt) map[*types.Var]bool {
+	vars := make(map[*types.Var]bool)
+	addVar := func(ident *ast.Ident) {
+		if obj, ok := v.pkg.TypesInfo.Defs[ident].(*types.Var); ok && !obj.IsField() {
+			vars[obj] = true
+		}
+	}
+	if typ.Params != nil {
+		for _, field := range typ.Params.List {
+			for _, name := range field.Names {
+				addVar(name)
+			}
+		}
+	}
+	inspectFunc(body, func(node ast.Node) {
+		if ident, ok := node.(*ast.Ident); ok {
+			addVar(ident)
+		}
+	})
+	return vars
+}
+
+// escapedVars returns the variables that are captured by the function literals within the provided function body
+// along with the number of times that the address of each variable is taken outside of the function literals, either
+// explicitly or by calling a method with a pointer receiver on it.
+func (v *visitor) escapedVars(body *ast.BlockStmt) (captured map[*types.Var]bool, addressed map[*types.Var]int) {
+	captured = make(map[*types.Var]bool)
+	addressed = make(map[*types.Var]int)
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			ast.Inspect(node.Body, func(node ast.Node) bool {
+				if ident, ok := node.(*ast.Ident); ok {
+					if obj, ok := v.pkg.TypesInfo.Uses[ident].(*types.Var); ok {
+						captured[obj] = true
+					}
+				}
+				return true
+			})
+			return false
+		case *ast.UnaryExpr:
+			if ident, ok := ast.Unparen(node.X).(*ast.Ident); ok && node.Op == token.AND {
+				if obj, ok := v.pkg.TypesInfo.Uses[ident].(*types.Var); ok {
+					addressed[obj]++
+				}
+			}
+		case *ast.SelectorExpr:
+			// calling a method with a pointer receiver on an addressable value takes its address
+			ident, ok := ast.Unparen(node.X).(*ast.Ident)
+			if !ok {
+				break
+			}
+			obj, ok := v.pkg.TypesInfo.Uses[ident].(*types.Var)
+			if !ok || isPointer(obj.Type()) {
+				break
+			}
+			if sel, ok := v.pkg.TypesInfo.Selections[node]; ok && sel.Kind() == types.MethodVal {
+				if isPointer(sel.Obj().Type().(*types.Signature).Recv().Type()) {
+					addressed[obj]++
+				}
+			}
+		}
+		return true
+	})
+	return captured, addressed
+}
+
+// inspectFunc calls the provided function for every node of the provided function body other than the nodes within
+// function literals.
+func inspectFunc(body *ast.BlockStmt, f func(node ast.Node)) {
+	ast.Inspect(body, func(node ast.Node) bool {
+		if _, ok := node.(*ast.FuncLit); ok {
+			return false
+		}
+		if node != nil {
+			f(node)
+		}
+		return true
+	})
+}
+
+// hasLabels returns true if the provided function body contains labeled statements, which can be the targets of
+// jumps, outside of the function literals within it.
+func hasLabels(body *ast.BlockStmt) bool {
+	found := false
+	inspectFunc(body, func(node ast.Node) {
+		if _, ok := node.(*ast.LabeledStmt); ok {
+			found = true
+		}
+	})
+	return found
+}
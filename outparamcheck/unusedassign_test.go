@@ -0,0 +1,113 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckUnusedAssignments(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+			"os"
+		)
+
+		type config struct{}
+
+		func (c *config) load() {}
+
+		func decode(data []byte) error {
+			var c config
+			err := json.Unmarshal(data, &c)
+			err = json.Unmarshal(data, &c)
+			if err != nil {
+				return err
+			}
+			err = os.Remove("config.json")
+			return nil
+		}
+
+		func results(data []byte) (err error) {
+			err = json.Unmarshal(data, nil)
+			err = json.Unmarshal(data, nil)
+			return
+		}
+
+		func param(data []byte) {
+			data = []byte("{}")
+		}
+
+		func used() error {
+			err := os.Remove("a")
+			if err != nil {
+				err = os.Remove("b")
+			}
+			return err
+		}
+
+		func loop() {
+			n := 0
+			for n < 10 {
+				n++
+			}
+			for i := 0; i < 10; i++ {
+				err := os.Remove("a")
+				if i > 5 {
+					continue
+				}
+				_ = err
+			}
+		}
+
+		func captured() {
+			err := os.Remove("a")
+			defer func() {
+				_ = err
+			}()
+			err = os.Remove("b")
+		}
+
+		func addressed() {
+			var c config
+			c = config{}
+			c.load()
+			c = config{}
+			p := &c
+			_ = p
+		}
+
+		func nilValues() {
+			var err error = nil
+			err = os.Remove("a")
+			_ = err
+		}
+
+		func main() {
+			f := func() {
+				err := os.Remove("a")
+				err = os.Remove("b")
+				_ = err
+			}
+			f()
+		}
+		`)
+	assertOutParamErrors(t, pkgs, []OutParamError{
+		{Pos: token.Position{Offset: 168, Line: 15, Column: 4}, Line: "err := json.Unmarshal(data, &c)", Problem: "value assigned to err is never used", Check: CheckUnusedAssignment},
+		{Pos: token.Position{Offset: 276, Line: 20, Column: 4}, Line: `err = os.Remove("config.json")`, Problem: "value assigned to err is never used", Check: CheckUnusedAssignment},
+		{Pos: token.Position{Offset: 484, Line: 31, Column: 4}, Line: `data = []byte("{}")`, Problem: "value assigned to data is never used", Check: CheckUnusedAssignment},
+		{Pos: token.Position{Offset: 1129, Line: 81, Column: 5}, Line: `err := os.Remove("a")`, Problem: "value assigned to err is never used", Check: CheckUnusedAssignment},
+	}, runChecks(t, pkgs, Config{}, CheckUnusedAssignment))
+}
@@ -0,0 +1,153 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// assignment is an assignment of a value to a variable by its declaration or by an assignment statement, whose value
+// is the value with the index of the variable in the right-hand side.
+type assignment struct {
+	rhs   []ast.Expr
+	index int
+	// end is the end of the statement or declaration, after which the variable has the value.
+	end token.Pos
+	// scope is the innermost block or case clause of a switch or select statement that contains the assignment, which
+	// is nil for the declarations of package-level variables. The body of a case clause is not a block statement, so the
+	// assignments in a case clause would otherwise be scoped to the body of the switch statement and dominate the
+	// positions in its other cases.
+	scope ast.Node
+}
+
+// dominates returns true if the assignment is made on every path to the provided position that follows it, which is
+// the case if the assignment is in a block or case clause that contains the position. Jumps are not taken into account.
+func (a assignment) dominates(pos token.Pos) bool {
+	return a.scope == nil || a.scope.Pos() <= pos && pos < a.scope.End()
+}
+
+// varAssignments are the assignments of the variables of the checked package.
+type varAssignments struct {
+	// assignments are the assignments of every variable in source order.
+	assignments map[*types.Var][]assignment
+	// addressed are the variables whose address is taken, which may be assigned through the pointer.
+	addressed map[*types.Var]bool
+}
+
+// reachingValues returns the assignments whose values the provided variable may have at the provided position, which
+// are the last assignment that precedes the position and is made on every path to it followed by the assignments that
+// follow it but only on some paths, such as the assignments in the branches of an if statement. It returns false if
+// the values are unknown because the variable is not assigned before the position, such as a parameter, or its
+// address is taken.
+func (v *visitor) reachingValues(obj *types.Var, pos token.Pos) ([]assignment, bool) {
+	if v.assignments == nil {
+		v.assignments = v.varAssignments()
+	}
+	if v.assignments.addressed[obj] {
+		return nil, false
+	}
+	return v.reachingAssignments(obj, pos)
+}
+
+// reachingAssignments returns the assignments whose values the provided variable may have at the provided position
+// like reachingValues, but ignores that the address of the variable may be taken, through which other values may be
+// assigned.
+func (v *visitor) reachingAssignments(obj *types.Var, pos token.Pos) ([]assignment, bool) {
+	if v.assignments == nil {
+		v.assignments = v.varAssignments()
+	}
+	assignments := v.assignments.assignments[obj]
+	// the assignments that are complete before the position
+	preceding := sort.Search(len(assignments), func(i int) bool {
+		return assignments[i].end > pos
+	})
+	for i := preceding - 1; i >= 0; i-- {
+		if assignments[i].dominates(pos) {
+			return assignments[i:preceding], true
+		}
+	}
+	return nil, false
+}
+
+// varAssignments returns the assignments of the variables of the checked package.
+func (v *visitor) varAssignments() *varAssignments {
+	va := &varAssignments{
+		assignments: make(map[*types.Var][]assignment),
+		addressed:   make(map[*types.Var]bool),
+	}
+	var scopes []ast.Node
+	assign := func(lhs []ast.Expr, rhs []ast.Expr, end token.Pos) {
+		var scope ast.Node
+		if len(scopes) > 0 {
+			scope = scopes[len(scopes)-1]
+		}
+		for i, expr := range lhs {
+			ident, ok := expr.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if obj, ok := v.pkg.TypesInfo.ObjectOf(ident).(*types.Var); ok {
+				va.assignments[obj] = append(va.assignments[obj], assignment{rhs: rhs, index: i, end: end, scope: scope})
+			}
+		}
+	}
+	for _, file := range v.pkg.Syntax {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if node == nil {
+				if len(scopes) > 0 {
+					scopes = scopes[:len(scopes)-1]
+				}
+				return true
+			}
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				if node.Tok == token.ASSIGN || node.Tok == token.DEFINE {
+					assign(node.Lhs, node.Rhs, node.End())
+				} else {
+					// the value of an assignment operation, such as "x += y", is unknown
+					assign(node.Lhs, nil, node.End())
+				}
+			case *ast.ValueSpec:
+				names := make([]ast.Expr, len(node.Names))
+				for i, name := range node.Names {
+					names[i] = name
+				}
+				assign(names, node.Values, node.End())
+			case *ast.RangeStmt:
+				// the values that are assigned by a range clause are unknown
+				assign([]ast.Expr{node.Key, node.Value}, nil, node.Body.Pos())
+			case *ast.IncDecStmt:
+				assign([]ast.Expr{node.X}, nil, node.End())
+			case *ast.UnaryExpr:
+				if ident, ok := node.X.(*ast.Ident); ok && node.Op == token.AND {
+					if obj, ok := v.pkg.TypesInfo.ObjectOf(ident).(*types.Var); ok {
+						va.addressed[obj] = true
+					}
+				}
+			}
+			// every node is pushed so that the stack can be popped when the traversal of its children is complete
+			var scope ast.Node
+			switch node.(type) {
+			case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+				scope = node
+			default:
+				if len(scopes) > 0 {
+					scope = scopes[len(scopes)-1]
+				}
+			}
+			scopes = append(scopes, scope)
+			return true
+		})
+	}
+	for _, assignments := range va.assignments {
+		sort.SliceStable(assignments, func(i, j int) bool {
+			return assignments[i].end < assignments[j].end
+		})
+	}
+	return va
+}
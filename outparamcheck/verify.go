@@ -0,0 +1,162 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// VerifyConfig verifies that every rule of the user-supplied configuration (the configuration provided by the
+// configuration parameter and by the configuration files that apply to the packages) matches a function or method
+// that is defined in the packages matched by the provided paths or in their dependencies, and that the matched
+// functions and methods have the arguments that the rule refers to. Rules that do not match anything are typically
+// left behind when a dependency renames a function or changes its signature. Each problem is printed to standard
+// output and an error is returned if there are any problems. The rules of the built-in configuration are not verified
+// because they refer to packages that are not necessarily dependencies of the checked packages.
+func VerifyConfig(opts Options, paths []string) error {
+	usrCfg, err := loadUserConfig(opts.Config)
+	if err != nil {
+		return err
+	}
+	pkgs, err := load(paths, opts.skippedDirs(), opts.SkipPackages, opts.testsMode(), nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	pkgCfgs, err := packageConfigs(pkgs, usrCfg)
+	if err != nil {
+		return err
+	}
+
+	rules := make(map[string]*Rule)
+	for _, pkg := range pkgs {
+		pkgCfg := pkgCfgs[pkg]
+		for key, rule := range pkgCfg.Rules {
+			if rule == nil {
+				continue
+			}
+			// record the effective matching mode since the rules of different configurations are combined
+			resolvedRule := *rule
+			resolvedRule.Match = pkgCfg.matchMode(rule)
+			rules[resolveModuleRelative(key, pkg.Module)] = &resolvedRule
+		}
+	}
+	problems := verifyRules(pkgs, rules)
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("%s in configuration", plural(len(problems), "problem", "problems"))
+	}
+	return nil
+}
+
+// verifyRules returns a description of every rule that does not match any function or method in the provided
+// packages or their dependencies, or that refers to an argument that none of the matched functions and methods have.
+func verifyRules(pkgs []*packages.Package, rules map[string]*Rule) []string {
+	funcs := definedFuncs(pkgs)
+
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		rule := rules[key]
+		if rule == nil {
+			continue
+		}
+		var matched []string
+		// the argument that requires the most parameters
+		var maxArg Arg
+		for _, arg := range rule.Args {
+			if arg.minArgs() > maxArg.minArgs() {
+				maxArg = arg
+			}
+		}
+		resolved := false
+		for funcKey, fn := range funcs {
+			if !matchesCall(funcKey, fn.pkgPath, strings.TrimPrefix(key, exclusionPrefix), rule, Config{}.matchMode(rule)) {
+				continue
+			}
+			matched = append(matched, funcKey)
+			if sig := fn.sig; maxArg.minArgs() <= sig.Params().Len() || sig.Variadic() {
+				resolved = true
+				break
+			}
+		}
+		switch {
+		case resolved:
+		case len(matched) == 0:
+			problems = append(problems, fmt.Sprintf("%s: does not match any function or method", key))
+		default:
+			sort.Strings(matched)
+			problems = append(problems, fmt.Sprintf("%s: argument %s does not exist in %s", key, maxArg, strings.Join(matched, ", ")))
+		}
+	}
+	return problems
+}
+
+// definedFunc is a function, method or function-typed struct field that is defined in a package.
+type definedFunc struct {
+	sig *types.Signature
+	// pkgPath is the import path of the package that defines the function, which is used to match rules.
+	pkgPath string
+}
+
+// definedFuncs returns the package-level functions and the methods and function-typed fields of the named types that are
+// defined in the provided packages and their dependencies, keyed in the same manner as the functions and methods
+// that are called in checked code. The methods of a named type T in package p are keyed both as "p.T.Method" and
+// "*p.T.Method". The functions of modules whose path has a major version suffix are also keyed by the import path of
+// their package without the suffix.
+func definedFuncs(pkgs []*packages.Package) map[string]definedFunc {
+	funcs := make(map[string]definedFunc)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Types == nil {
+			return
+		}
+		unversioned := unversionedPkgPath(pkg.Types.Path(), pkg.Module)
+		add := func(key string, sig *types.Signature) {
+			funcs[key] = definedFunc{sig: sig, pkgPath: pkg.Types.Path()}
+			if unversioned != pkg.Types.Path() {
+				funcs[strings.Replace(key, pkg.Types.Path(), unversioned, 1)] = definedFunc{sig: sig, pkgPath: unversioned}
+			}
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			switch obj := scope.Lookup(name).(type) {
+			case *types.Func:
+				add(fmt.Sprintf("%v.%v", pkg.Types.Path(), obj.Name()), obj.Type().(*types.Signature))
+			case *types.TypeName:
+				typ := obj.Type()
+				methodSet := types.NewMethodSet(types.NewPointer(typ))
+				if types.IsInterface(typ) {
+					methodSet = types.NewMethodSet(typ)
+				}
+				for i := 0; i < methodSet.Len(); i++ {
+					method := methodSet.At(i).Obj().(*types.Func)
+					add(fmt.Sprintf("%v.%v", typeKey(typ), method.Name()), method.Type().(*types.Signature))
+					add(fmt.Sprintf("*%v.%v", typeKey(typ), method.Name()), method.Type().(*types.Signature))
+				}
+				if st, ok := typ.Underlying().(*types.Struct); ok {
+					for i := 0; i < st.NumFields(); i++ {
+						if sig, ok := st.Field(i).Type().Underlying().(*types.Signature); ok {
+							add(fmt.Sprintf("%v.%v", typeKey(typ), st.Field(i).Name()), sig)
+							add(fmt.Sprintf("*%v.%v", typeKey(typ), st.Field(i).Name()), sig)
+						}
+					}
+				}
+			}
+		}
+	})
+	return funcs
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRules(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		import (
+			"encoding/json"
+			"fmt"
+		)
+
+		func main() {
+			fmt.Println(json.Valid(nil))
+		}
+		`)
+
+	problems := verifyRules(pkgs, map[string]*Rule{
+		"encoding/json.Unmarshal":       {Args: indexArgs(1)},
+		"encoding/json.Decoder.Decode":  {Args: indexArgs(0)},
+		"encoding/json.Marshaler.Foo":   {Args: indexArgs(0)},
+		"encoding/json.Unmarshaler.Bar": {Args: indexArgs(0)},
+		"encoding/json.Unmarshal2":      {Args: indexArgs(0)},
+		"encoding/json.Valid":           {Args: indexArgs(1)},
+		"fmt.Sscan":                     {Args: indexArgs(5)},
+		"encoding/json.Compact":         {Args: indexArgs(-2)},
+		"encoding/json.HTMLEscape":      {Args: indexArgs(-3)},
+		"removed.Func":                  nil,
+	})
+	assert.Equal(t, []string{
+		"encoding/json.HTMLEscape: argument -3 does not exist in encoding/json.HTMLEscape",
+		"encoding/json.Marshaler.Foo: does not match any function or method",
+		"encoding/json.Unmarshal2: does not match any function or method",
+		"encoding/json.Unmarshaler.Bar: does not match any function or method",
+		"encoding/json.Valid: argument 1 does not exist in encoding/json.Valid",
+	}, problems)
+}
+
+func TestVerifyRulesFuncFields(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		type Codec struct {
+			UnmarshalFn func(data []byte, v interface{}) error
+		}
+
+		func main() {}
+		`)
+
+	problems := verifyRules(pkgs, map[string]*Rule{
+		pkgs[0].PkgPath + ".Codec.UnmarshalFn":     {Args: indexArgs(1)},
+		"*" + pkgs[0].PkgPath + ".Codec.MarshalFn": {Args: indexArgs(0)},
+	})
+	assert.Equal(t, []string{
+		"*" + pkgs[0].PkgPath + ".Codec.MarshalFn: does not match any function or method",
+	}, problems)
+}
+
+func TestVerifyRulesGenericTypes(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	pkgs := loadTestPackage(t, tmpDir, `
+		package main
+
+		type Codec[T any] struct{}
+
+		func (c *Codec[T]) Decode(data []byte, v interface{}) error { return nil }
+
+		func main() {}
+		`)
+
+	problems := verifyRules(pkgs, map[string]*Rule{
+		pkgs[0].PkgPath + ".Codec.Decode":    {Args: indexArgs(1)},
+		pkgs[0].PkgPath + ".Codec[T].Decode": {Args: indexArgs(1)},
+		pkgs[0].PkgPath + ".Codec.Encode":    {Args: indexArgs(0)},
+	})
+	assert.Equal(t, []string{
+		pkgs[0].PkgPath + ".Codec.Encode: does not match any function or method",
+	}, problems)
+}
@@ -0,0 +1,108 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// allPackagesPattern is the pattern that matches all of the packages in the current directory and its subdirectories.
+const allPackagesPattern = "./..."
+
+// expandWorkspacePatterns returns the provided package patterns with the "./..." pattern expanded to the packages of
+// every module of the Go workspace if the working directory is the root of a Go workspace. The go command does not
+// match the packages of workspace modules using "./..." from the root of a workspace that is not itself a module, so
+// the expansion allows all of the modules of a workspace to be checked in a single invocation.
+func expandWorkspacePatterns(patterns []string) ([]string, error) {
+	expand := false
+	for _, pattern := range patterns {
+		if pattern == allPackagesPattern {
+			expand = true
+		}
+	}
+	if !expand {
+		return patterns, nil
+	}
+
+	moduleDirs, err := workspaceModuleDirs(".")
+	if err != nil {
+		return nil, err
+	}
+	if moduleDirs == nil {
+		return patterns, nil
+	}
+
+	var expanded []string
+	for _, pattern := range patterns {
+		if pattern != allPackagesPattern {
+			expanded = append(expanded, pattern)
+			continue
+		}
+		for _, moduleDir := range moduleDirs {
+			expanded = append(expanded, "./"+filepath.ToSlash(filepath.Join(moduleDir, "...")))
+		}
+	}
+	return expanded, nil
+}
+
+// workspaceModuleDirs returns the directories of the modules of the Go workspace relative to the provided directory if
+// the directory is the root of a Go workspace (contains the go.work file of the active workspace). Returns nil if the
+// directory is not the root of a Go workspace.
+func workspaceModuleDirs(dir string) ([]string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to determine absolute path of %s", dir)
+	}
+	goWork, err := runGo(absDir, "env", "GOWORK")
+	if err != nil {
+		return nil, err
+	}
+	goWork = strings.TrimSpace(goWork)
+	if goWork == "" || goWork == "off" || filepath.Dir(goWork) != absDir {
+		return nil, nil
+	}
+
+	goWorkJSON, err := runGo(absDir, "work", "edit", "-json", goWork)
+	if err != nil {
+		return nil, err
+	}
+	var workFile struct {
+		Use []struct {
+			DiskPath string
+		}
+	}
+	if err := json.Unmarshal([]byte(goWorkJSON), &workFile); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", goWork)
+	}
+	moduleDirs := []string{}
+	for _, use := range workFile.Use {
+		moduleDir := filepath.FromSlash(use.DiskPath)
+		if filepath.IsAbs(moduleDir) {
+			if moduleDir, err = filepath.Rel(absDir, moduleDir); err != nil {
+				return nil, errors.Wrapf(err, "failed to determine relative path of %s", use.DiskPath)
+			}
+		}
+		moduleDirs = append(moduleDirs, filepath.Clean(moduleDir))
+	}
+	return moduleDirs, nil
+}
+
+func runGo(dir string, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "go %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceModuleDirs(t *testing.T) {
+	// workspace mode does not support -mod=mod
+	t.Setenv("GOFLAGS", "")
+	t.Setenv("GOWORK", "")
+
+	tmpDir, cleanup, err := dirs.TempDir("", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	for file, content := range map[string]string{
+		"go.work":           "go 1.23\n\nuse (\n\t./a\n\t./nested/b\n)\n",
+		"a/go.mod":          "module example.com/a\n\ngo 1.23\n",
+		"nested/b/go.mod":   "module example.com/b\n\ngo 1.23\n",
+		"standalone/go.mod": "module example.com/standalone\n\ngo 1.23\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, file)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, file), []byte(content), 0644))
+	}
+
+	moduleDirs, err := workspaceModuleDirs(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", filepath.Join("nested", "b")}, moduleDirs)
+
+	// a module of the workspace is not the root of the workspace
+	moduleDirs, err = workspaceModuleDirs(filepath.Join(tmpDir, "a"))
+	require.NoError(t, err)
+	assert.Nil(t, moduleDirs)
+}
@@ -0,0 +1,234 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/packages"
+)
+
+// wrapperInferenceDepth is the maximum number of calls between a wrapper and the output parameter that it passes its
+// parameter to. A depth of 3 infers the wrappers that call a checked function directly as well as the wrappers of
+// those wrappers up to two additional hops.
+const wrapperInferenceDepth = 3
+
+// wrapperOutParams are the output parameters of wrapper functions keyed by function and parameter index.
+type wrapperOutParams map[*types.Func]map[int]*outArg
+
+// wrappers are the wrapper functions inferred from the checked packages.
+type wrappers struct {
+	outParams wrapperOutParams
+	// forwarded are the positions of the arguments through which wrappers pass their parameters to output parameters,
+	// which are not reported since the callers of the wrappers are checked instead.
+	forwarded map[token.Pos]bool
+}
+
+// params returns the output parameters of the provided function if it is a wrapper.
+func (w *wrappers) params(fn *types.Func) map[int]*outArg {
+	if w == nil {
+		return nil
+	}
+	return w.outParams[fn]
+}
+
+// forwards returns true if the argument at the provided position passes a parameter of a wrapper to an output
+// parameter.
+func (w *wrappers) forwards(pos token.Pos) bool {
+	return w != nil && w.forwarded[pos]
+}
+
+// inferWrappers returns the output parameters of the functions and methods of the provided packages that pass one of
+// their parameters directly to an output parameter, either of a function that is checked according to the configuration
+// returned for its package by pkgCfg or of another wrapper. The parameters must not be reassigned or have their address
+// taken, so that the argument of the wrapper is the value that reaches the output parameter.
+func inferWrappers(pkgs []*packages.Package, pkgCfg func(pkg *packages.Package) Config) *wrappers {
+	w := &wrappers{
+		outParams: make(wrapperOutParams),
+		forwarded: make(map[token.Pos]bool),
+	}
+	var visitors []*visitor
+	for _, pkg := range pkgs {
+		cfg := pkgCfg(pkg)
+		if cfg.excludesPackage(pkg.PkgPath) {
+			continue
+		}
+		visitors = append(visitors, &visitor{
+			pkg:      pkg,
+			cfg:      cfg,
+			wrappers: w,
+		})
+	}
+	for depth := 0; depth < wrapperInferenceDepth; depth++ {
+		// the wrappers found at this depth, which are added once every package has been inspected so that every wrapper
+		// found at this depth calls a wrapper found at a lower depth
+		found := make(wrapperOutParams)
+		for _, v := range visitors {
+			v.findWrappers(found)
+		}
+		if len(found) == 0 {
+			break
+		}
+		for fn, params := range found {
+			if w.outParams[fn] == nil {
+				w.outParams[fn] = make(map[int]*outArg)
+			}
+			for i, out := range params {
+				w.outParams[fn][i] = out
+			}
+		}
+	}
+	return w
+}
+
+// findWrappers adds the output parameters of the functions of the checked package that pass their parameters to the
+// output parameters of calls that are not yet known to the provided map of output parameters.
+func (v *visitor) findWrappers(found wrapperOutParams) {
+	for _, file := range v.pkg.Syntax {
+		if isCgoGenerated(v.pkg, file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil {
+				continue
+			}
+			fn, ok := v.pkg.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			sig := fn.Type().(*types.Signature)
+			params := make(map[*types.Var]int)
+			for i := 0; i < sig.Params().Len(); i++ {
+				// variadic parameters are passed as a slice, which is not an output parameter
+				if !sig.Variadic() || i < sig.Params().Len()-1 {
+					params[sig.Params().At(i)] = i
+				}
+			}
+			ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				_, outArgs := v.callOutArgs(call)
+				for i, out := range outArgs {
+					ident, ok := ast.Unparen(call.Args[i]).(*ast.Ident)
+					if !ok {
+						continue
+					}
+					param, ok := v.pkg.TypesInfo.Uses[ident].(*types.Var)
+					if !ok {
+						continue
+					}
+					index, ok := params[param]
+					if !ok || v.isReassigned(param) {
+						continue
+					}
+					v.wrappers.forwarded[call.Args[i].Pos()] = true
+					if v.wrappers.params(fn)[index] != nil {
+						continue
+					}
+					if found[fn] == nil {
+						found[fn] = make(map[int]*outArg)
+					}
+					if curr, ok := found[fn][index]; !ok || out.severity < curr.severity {
+						found[fn][index] = &outArg{rule: out.rule, severity: out.severity, kind: out.kind, disallowNil: out.disallowNil}
+					}
+				}
+				return true
+			})
+		}
+	}
+}
+
+// isReassigned returns true if the provided parameter is assigned in the body of its function or its address is taken.
+func (v *visitor) isReassigned(param *types.Var) bool {
+	if v.assignments == nil {
+		v.assignments = v.varAssignments()
+	}
+	return len(v.assignments.assignments[param]) > 0 || v.assignments.addressed[param]
+}
+
+// config returns a configuration whose rules specify the output parameters of the inferred wrappers that can be called
+// from other modules, which are the exported functions and the exported methods of exported types that are not
+// declared in test files. The configuration can be extended by the configurations of the modules that depend on the
+// checked packages so that the callers of the wrappers are checked without inferring the wrappers again.
+func (w *wrappers) config(fset *token.FileSet) Config {
+	cfg := Config{Rules: make(map[string]*Rule)}
+	for fn, params := range w.outParams {
+		name, ok := exportedFuncName(fn)
+		if !ok || strings.HasSuffix(fset.Position(fn.Pos()).Filename, "_test.go") {
+			continue
+		}
+		rule := cfg.Rules[name]
+		if rule == nil {
+			rule = &Rule{Severity: SeverityWarning}
+			cfg.Rules[name] = rule
+		}
+		for i, out := range params {
+			if out.severity < rule.Severity {
+				rule.Severity = out.severity
+			}
+			if !containsArgIndex(rule.Args, i) {
+				rule.Args = append(rule.Args, Arg{Index: i, Kind: out.kind})
+			}
+			if out.disallowNil {
+				allowNil := false
+				rule.AllowNil = &allowNil
+			}
+		}
+	}
+	return cfg
+}
+
+// exportedFuncName returns the name of the rule that matches the provided function, which is the import path of its
+// package followed by the name of its receiver type (if it is a method) and its name. It returns false if the function
+// cannot be called from other packages.
+func exportedFuncName(fn *types.Func) (string, bool) {
+	if !fn.Exported() || fn.Pkg() == nil || strings.HasSuffix(fn.Pkg().Path(), "_test") {
+		return "", false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return fn.Pkg().Path() + "." + fn.Name(), true
+	}
+	recvType := recv.Type()
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		recvType = ptr.Elem()
+	}
+	named, ok := types.Unalias(recvType).(*types.Named)
+	if !ok || !named.Obj().Exported() {
+		return "", false
+	}
+	return fn.Pkg().Path() + "." + named.Obj().Name() + "." + fn.Name(), true
+}
+
+// containsArgIndex returns true if one of the provided arguments has the provided index.
+func containsArgIndex(args []Arg, index int) bool {
+	for _, arg := range args {
+		if arg.Index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// writeWrapperRules writes the configuration of the rules of the provided wrappers to the file with the provided path.
+func writeWrapperRules(path string, w *wrappers, fset *token.FileSet) error {
+	buf := &bytes.Buffer{}
+	if err := w.config(fset).Print(buf); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write wrapper rules %s", path)
+	}
+	return nil
+}
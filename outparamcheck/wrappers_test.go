@@ -0,0 +1,69 @@
+// Copyright 2016 Palantir Technologies, Inc. All rights reserved.
+// Licensed under the MIT License. See LICENSE in the project root
+// for license information.
+
+package outparamcheck
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/nmiyake/pkg/dirs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestWriteWrapperRules(t *testing.T) {
+	tmpDir, cleanup, err := dirs.TempDir(".", "")
+	require.NoError(t, err)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "lib.go"), []byte(`package lib
+
+import (
+	"encoding/json"
+)
+
+type Client struct{}
+
+type client struct{}
+
+func Decode(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+func (*Client) Get(path string, out, opts interface{}) error { return Decode(nil, out) }
+
+func (Client) Post(path string, in, out interface{}) error { return json.Unmarshal(nil, out) }
+
+func (client) Get(path string, out interface{}) error { return Decode(nil, out) }
+
+func decode(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(path.Join(tmpDir, "lib_test.go"), []byte(`package lib
+
+func DecodeTest(data []byte, v interface{}) error { return Decode(data, v) }
+`), 0644))
+
+	pkgs, err := load([]string{"./" + tmpDir}, nil, nil, testsAll, nil)
+	require.NoError(t, err)
+	cfg := Config{
+		Rules: map[string]*Rule{
+			"encoding/json.Unmarshal": {Args: []Arg{{Index: 1, Kind: ArgKindPtrToStruct}}},
+		},
+	}
+	w := inferWrappers(pkgs, func(*packages.Package) Config {
+		return cfg
+	})
+	rulesPath := path.Join(tmpDir, "rules.json")
+	require.NoError(t, writeWrapperRules(rulesPath, w, pkgs[0].Fset))
+
+	rules, err := loadCfgFromPath(rulesPath)
+	require.NoError(t, err)
+	pkgPath := pkgs[0].PkgPath
+	assert.Equal(t, map[string]*Rule{
+		pkgPath + ".Decode":      {Args: []Arg{{Index: 1, Kind: ArgKindPtrToStruct}}},
+		pkgPath + ".Client.Get":  {Args: []Arg{{Index: 1, Kind: ArgKindPtrToStruct}}},
+		pkgPath + ".Client.Post": {Args: []Arg{{Index: 2, Kind: ArgKindPtrToStruct}}},
+	}, rules.Rules)
+}